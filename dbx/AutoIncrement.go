@@ -0,0 +1,165 @@
+package dbx
+
+import (
+	"database/sql"
+	"github.com/meiguonet/mgboot-go-common/util/castx"
+	"reflect"
+	"sync"
+)
+
+const (
+	batchInsertIdModeRowByRow = iota
+	batchInsertIdModeConsecutive
+	batchInsertIdModeReturning
+)
+
+type autoIncrementSettings struct {
+	lockMode  int64
+	increment int64
+}
+
+var autoIncrementCache *autoIncrementSettings
+var autoIncrementLock = &sync.Mutex{}
+
+func resetAutoIncrementSettings() {
+	autoIncrementLock.Lock()
+	defer autoIncrementLock.Unlock()
+	autoIncrementCache = nil
+}
+
+func getAutoIncrementSettings(tx *sql.Tx) *autoIncrementSettings {
+	autoIncrementLock.Lock()
+	cached := autoIncrementCache
+	autoIncrementLock.Unlock()
+
+	if cached != nil {
+		return cached
+	}
+
+	query := "SELECT @@innodb_autoinc_lock_mode AS lockMode, @@auto_increment_increment AS increment"
+	var list []map[string]interface{}
+	var err error
+
+	if tx != nil {
+		list, err = TxSelectBySql(tx, query)
+	} else {
+		list, err = SelectBySql(query)
+	}
+
+	if err != nil || len(list) < 1 {
+		return nil
+	}
+
+	settings := &autoIncrementSettings{
+		lockMode:  castx.ToInt64(list[0]["lockMode"], 2),
+		increment: castx.ToInt64(list[0]["increment"], 1),
+	}
+
+	if settings.increment < 1 {
+		settings.increment = 1
+	}
+
+	autoIncrementLock.Lock()
+	defer autoIncrementLock.Unlock()
+
+	if autoIncrementCache == nil {
+		autoIncrementCache = settings
+	}
+
+	return autoIncrementCache
+}
+
+func getBatchInsertIdMode(tx *sql.Tx) int {
+	switch getDialect().Name() {
+	case "postgres":
+		return batchInsertIdModeReturning
+	case "mysql":
+		settings := getAutoIncrementSettings(tx)
+
+		if settings != nil && settings.lockMode < 2 {
+			return batchInsertIdModeConsecutive
+		}
+	}
+
+	return batchInsertIdModeRowByRow
+}
+
+func getAutoIncrementStep(tx *sql.Tx) int64 {
+	if settings := getAutoIncrementSettings(tx); settings != nil {
+		return settings.increment
+	}
+
+	return 1
+}
+
+func setPkValueToModel(field reflect.Value, id int64) {
+	if !field.IsValid() || !field.CanSet() {
+		return
+	}
+
+	if field.Kind() == reflect.Ptr {
+		switch field.Type().Elem().Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		default:
+			return
+		}
+
+		if field.IsNil() {
+			field.Set(reflect.New(field.Type().Elem()))
+		}
+
+		field = field.Elem()
+	}
+
+	switch field.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		field.SetInt(id)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if id >= 0 {
+			field.SetUint(uint64(id))
+		}
+	}
+}
+
+func collectModelValues(models interface{}) ([]reflect.Value, error) {
+	rv := reflect.ValueOf(models)
+
+	if rv.Kind() == reflect.Ptr {
+		rv = rv.Elem()
+	}
+
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, NewDbException("in dbx.BatchInsertByModels function, models must be a slice of struct or struct pointer")
+	}
+
+	list := make([]reflect.Value, 0, rv.Len())
+
+	for i := 0; i < rv.Len(); i++ {
+		item := rv.Index(i)
+
+		if item.Kind() == reflect.Interface {
+			item = item.Elem()
+		}
+
+		if item.Kind() == reflect.Ptr {
+			if item.IsNil() {
+				continue
+			}
+
+			item = item.Elem()
+		}
+
+		if item.Kind() != reflect.Struct {
+			return nil, NewDbException("in dbx.BatchInsertByModels function, models must be a slice of struct or struct pointer")
+		}
+
+		if !item.CanAddr() {
+			return nil, NewDbException("in dbx.BatchInsertByModels function, models must be addressable, pass a slice or a pointer to array")
+		}
+
+		list = append(list, item)
+	}
+
+	return list, nil
+}
@@ -0,0 +1,75 @@
+package dbx
+
+import (
+	"database/sql"
+	"testing"
+)
+
+type batchItem struct {
+	Id   int64  `db:"id,pk,autoincr"`
+	Name string `db:"name"`
+}
+
+func TestBatchInsertByModelsWritesBackGeneratedIds(t *testing.T) {
+	openSqliteTestDb(t, "CREATE TABLE items (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT)")
+	list := []interface{}{&batchItem{Name: "a"}, &batchItem{Name: "b"}, &batchItem{Name: "c"}}
+
+	if n, err := Table("items").BatchInsertByModels(list); err != nil || n != 3 {
+		t.Fatalf("BatchInsertByModels() = %d, %v, want 3", n, err)
+	}
+
+	for idx, item := range list {
+		if id := item.(*batchItem).Id; id != int64(idx+1) {
+			t.Fatalf("list[%d].Id = %d, want %d", idx, id, idx+1)
+		}
+	}
+}
+
+func TestBatchInsertByModelsRejectsUnaddressableInput(t *testing.T) {
+	openSqliteTestDb(t, "CREATE TABLE items (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT)")
+	arr := [2]batchItem{{Name: "a"}, {Name: "b"}}
+
+	if _, err := Table("items").BatchInsertByModels(arr); err == nil {
+		t.Fatal("array passed by value accepted")
+	}
+
+	if _, err := Table("items").BatchInsertByModels(&arr); err != nil {
+		t.Fatal(err)
+	}
+
+	if arr[0].Id != 1 || arr[1].Id != 2 {
+		t.Fatalf("ids = %d, %d, want 1, 2", arr[0].Id, arr[1].Id)
+	}
+}
+
+func TestBatchInsertSqlUsesDefaultForMissingColumns(t *testing.T) {
+	rows := []map[string]interface{}{{"name": "a"}, {"name": "b", "status": 2}}
+	query, params, err := Table("items").buildBatchInsertSql(rows)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "INSERT INTO `items` (`name`, `status`) VALUES (?, DEFAULT), (?, ?)"; query != want {
+		t.Fatalf("sql = %q, want %q", query, want)
+	}
+
+	if len(params) != 3 {
+		t.Fatalf("params = %v", params)
+	}
+}
+
+func TestBatchInsertReturningChecksIdCount(t *testing.T) {
+	openSqliteTestDb(t, "CREATE TABLE items (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT)")
+	items, _ := collectModelValues([]*batchItem{{Name: "a"}, {Name: "b"}})
+	rows := []map[string]interface{}{{"name": "a"}, {"name": "b"}}
+
+	err := Transations(func(tx *sql.Tx) error {
+		_, err := Table("items").batchInsertReturning(tx, rows, items, []string{"Id", "Id"})
+		return err
+	})
+
+	if err == nil {
+		t.Fatal("missing RETURNING ids not reported")
+	}
+}
@@ -0,0 +1,251 @@
+package dbx
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+type conventions struct {
+	createTimeColumns []string
+	updateTimeColumns []string
+	deleteTimeColumns []string
+	deleteFlagColumns []string
+	versionColumns    []string
+	useDbNow          bool
+	unixTimeUnit      time.Duration
+	loc               *time.Location
+}
+
+var defaultConventions = NewConventions()
+var tableConventions = map[string]*conventions{}
+var conventionsLock = &sync.RWMutex{}
+
+func NewConventions() *conventions {
+	return &conventions{
+		createTimeColumns: []string{"ctime", "create_at", "createAt", "create_time", "createTime"},
+		updateTimeColumns: []string{"update_at", "updateAt", "update_time", "updateTime"},
+		deleteTimeColumns: []string{"delete_at", "deleteAt", "delete_time", "deleteTime"},
+		deleteFlagColumns: []string{"del_flag", "delFlag"},
+		versionColumns:    []string{"version"},
+		unixTimeUnit:      time.Second,
+		loc:               time.Local,
+	}
+}
+
+func (c *conventions) WithCreateTimeColumns(columnNames ...string) *conventions {
+	c.createTimeColumns = columnNames
+	return c
+}
+
+func (c *conventions) WithUpdateTimeColumns(columnNames ...string) *conventions {
+	c.updateTimeColumns = columnNames
+	return c
+}
+
+func (c *conventions) WithDeleteTimeColumns(columnNames ...string) *conventions {
+	c.deleteTimeColumns = columnNames
+	return c
+}
+
+func (c *conventions) WithDeleteFlagColumns(columnNames ...string) *conventions {
+	c.deleteFlagColumns = columnNames
+	return c
+}
+
+func (c *conventions) WithVersionColumns(columnNames ...string) *conventions {
+	c.versionColumns = columnNames
+	return c
+}
+
+func (c *conventions) WithDbNow(flag bool) *conventions {
+	c.useDbNow = flag
+	return c
+}
+
+func (c *conventions) WithUnixTimeUnit(unit time.Duration) *conventions {
+	if unit == time.Second || unit == time.Millisecond {
+		c.unixTimeUnit = unit
+	}
+
+	return c
+}
+
+func (c *conventions) WithTimeZone(loc *time.Location) *conventions {
+	if loc != nil {
+		c.loc = loc
+	}
+
+	return c
+}
+
+func WithConventions(c *conventions, tableNames ...string) {
+	if c == nil {
+		return
+	}
+
+	conventionsLock.Lock()
+	defer conventionsLock.Unlock()
+
+	if len(tableNames) < 1 {
+		defaultConventions = c
+		return
+	}
+
+	for _, tableName := range tableNames {
+		tableConventions[normalizeTableName(tableName)] = c
+	}
+}
+
+func getConventions(tableName string) *conventions {
+	conventionsLock.RLock()
+	defer conventionsLock.RUnlock()
+
+	if c, ok := tableConventions[normalizeTableName(tableName)]; ok {
+		return c
+	}
+
+	return defaultConventions
+}
+
+func (c *conventions) now() time.Time {
+	return time.Now().In(c.loc)
+}
+
+func (c *conventions) findField(tableName string, columnNames []string, accept func(TableFieldInfo) bool) (TableFieldInfo, bool) {
+	schemas := getTableFields(tableName)
+
+	for _, columnName := range columnNames {
+		for _, item := range schemas {
+			if item.FieldName == columnName && accept(item) {
+				return item, true
+			}
+		}
+	}
+
+	return TableFieldInfo{}, false
+}
+
+func (c *conventions) timeValueForField(field TableFieldInfo, t1 *time.Time) (interface{}, bool) {
+	kind := getTimeColumnKind(field)
+
+	if kind == "" {
+		return nil, false
+	}
+
+	if t1 == nil {
+		if c.useDbNow {
+			return c.dbNowExpr(kind), true
+		}
+
+		t := c.now()
+		t1 = &t
+	}
+
+	t := t1.In(c.loc)
+
+	switch kind {
+	case "datetime":
+		return t.Format(dateFormatFull), true
+	case "date":
+		return t.Format(dateFormatDateOnly), true
+	case "unix":
+		if c.unixTimeUnit == time.Millisecond {
+			return t.UnixNano() / int64(time.Millisecond), true
+		}
+
+		return t.Unix(), true
+	}
+
+	return nil, false
+}
+
+func (c *conventions) dbNowExpr(kind string) *rawSql {
+	switch kind {
+	case "date":
+		return Raw("CURRENT_DATE")
+	case "unix":
+		return Raw(getDialect().UnixTimestampExpr(c.unixTimeUnit == time.Millisecond))
+	}
+
+	return Raw("CURRENT_TIMESTAMP")
+}
+
+func getTimeColumnKind(field TableFieldInfo) string {
+	fieldType := strings.ToLower(field.FieldType)
+
+	switch {
+	case strings.Contains(fieldType, "datetime"), strings.Contains(fieldType, "timestamp"):
+		return "datetime"
+	case fieldType == "date":
+		return "date"
+	case strings.Contains(fieldType, "int"):
+		return "unix"
+	}
+
+	return ""
+}
+
+func getSoftDeleteField(tableName string) (field TableFieldInfo, isFlag bool, ok bool) {
+	c := getConventions(tableName)
+
+	field, ok = c.findField(tableName, c.deleteFlagColumns, func(item TableFieldInfo) bool {
+		return strings.Contains(strings.ToLower(item.FieldType), "int") || item.FieldType == "bool" || item.FieldType == "boolean"
+	})
+
+	if ok {
+		return field, true, true
+	}
+
+	field, ok = c.findField(tableName, c.deleteTimeColumns, func(item TableFieldInfo) bool {
+		return getTimeColumnKind(item) != ""
+	})
+
+	return field, false, ok
+}
+
+func buildSoftDeleteCondition(columnName string, field TableFieldInfo, isFlag, trashed bool) string {
+	columnName = quote(columnName)
+
+	if isFlag {
+		if trashed {
+			return columnName + " = 1"
+		}
+
+		return columnName + " = 0"
+	}
+
+	if getTimeColumnKind(field) == "unix" {
+		if trashed {
+			return columnName + " > 0"
+		}
+
+		return "(" + columnName + " IS NULL OR " + columnName + " = 0)"
+	}
+
+	if trashed {
+		return columnName + " IS NOT NULL"
+	}
+
+	return columnName + " IS NULL"
+}
+
+func buildSoftDeleteData(tableName string) map[string]interface{} {
+	map1 := map[string]interface{}{}
+	field, isFlag, ok := getSoftDeleteField(tableName)
+
+	if !ok {
+		return map1
+	}
+
+	if isFlag {
+		map1[field.FieldName] = 1
+		return map1
+	}
+
+	if value, ok := getConventions(tableName).timeValueForField(field, nil); ok {
+		map1[field.FieldName] = value
+	}
+
+	return map1
+}
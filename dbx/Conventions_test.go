@@ -0,0 +1,46 @@
+package dbx
+
+import (
+	"testing"
+)
+
+func TestDeleteUsesSoftDeleteColumn(t *testing.T) {
+	db := openSqliteTestDb(t,
+		"CREATE TABLE posts (id INTEGER PRIMARY KEY AUTOINCREMENT, title TEXT, del_flag INTEGER NOT NULL DEFAULT 0)",
+		"CREATE TABLE logs (id INTEGER PRIMARY KEY AUTOINCREMENT, msg TEXT)",
+		"INSERT INTO posts (title) VALUES ('a'), ('b')",
+		"INSERT INTO logs (msg) VALUES ('a'), ('b')",
+	)
+
+	if _, err := Table("posts").Where("id", 1).Delete(); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := countRows(t, db, "SELECT COUNT(*) FROM posts WHERE id = 1 AND del_flag = 1"); n != 1 {
+		t.Fatalf("Delete on soft-delete table removed or kept row unmarked, count = %d", n)
+	}
+
+	if n, err := Table("posts").Count(); err != nil || n != 1 {
+		t.Fatalf("Count() = %d, %v, want 1", n, err)
+	}
+
+	if n, err := Table("posts").WithTrashed().Count(); err != nil || n != 2 {
+		t.Fatalf("WithTrashed().Count() = %d, %v, want 2", n, err)
+	}
+
+	if _, err := Table("posts").Where("id", 1).ForceDelete(); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := countRows(t, db, "SELECT COUNT(*) FROM posts"); n != 1 {
+		t.Fatalf("ForceDelete left %d rows, want 1", n)
+	}
+
+	if _, err := Table("logs").Where("id", 1).Delete(); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := countRows(t, db, "SELECT COUNT(*) FROM logs"); n != 1 {
+		t.Fatalf("Delete on plain table left %d rows, want 1", n)
+	}
+}
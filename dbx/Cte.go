@@ -0,0 +1,167 @@
+package dbx
+
+import (
+	"strings"
+)
+
+type cte struct {
+	name    string
+	columns []string
+	qb      *queryBuilder
+	raw     *rawSql
+}
+
+// WithCte adds a common table expression named name, optionally with a column list such as
+// "paid(user_id)", built from a query builder or Raw. It is not called With because With already
+// loads relations.
+func (qb *queryBuilder) WithCte(name string, query interface{}) *queryBuilder {
+	return qb.addCte(name, query)
+}
+
+// WithRecursiveCte adds a CTE and marks the WITH clause RECURSIVE. The builder has no UNION, so
+// the anchor and recursive members must be written together as Raw.
+func (qb *queryBuilder) WithRecursiveCte(name string, query interface{}) *queryBuilder {
+	qb.recursiveCte = true
+	return qb.addCte(name, query)
+}
+
+func (qb *queryBuilder) addCte(name string, query interface{}) *queryBuilder {
+	name = strings.TrimSpace(name)
+
+	if name == "" {
+		return qb
+	}
+
+	item := cte{}
+
+	switch v := query.(type) {
+	case *queryBuilder:
+		if v == nil || v == qb {
+			return qb
+		}
+
+		item.qb = v
+	case *rawSql:
+		if v == nil || v.expr == "" {
+			return qb
+		}
+
+		item.raw = v
+	case rawSql:
+		if v.expr == "" {
+			return qb
+		}
+
+		item.raw = &v
+	default:
+		return qb
+	}
+
+	if strings.Contains(name, "(") && strings.HasSuffix(name, ")") {
+		s1 := strings.TrimSuffix(substringAfter(name, "("), ")")
+		name = strings.TrimSpace(substringBefore(name, "("))
+
+		for _, columnName := range regexpCommaSep.Split(strings.TrimSpace(s1), -1) {
+			if columnName != "" {
+				item.columns = append(item.columns, columnName)
+			}
+		}
+	}
+
+	if !isValidAlias(name) {
+		return qb.setErr(newInvalidIdentifierException("cte", name))
+	}
+
+	for _, columnName := range item.columns {
+		if !isValidAlias(columnName) {
+			return qb.setErr(newInvalidIdentifierException("cte column", columnName))
+		}
+	}
+
+	item.name = name
+
+	for idx, c := range qb.ctes {
+		if c.name == name {
+			qb.ctes[idx] = item
+			return qb
+		}
+	}
+
+	qb.ctes = append(qb.ctes, item)
+	return qb
+}
+
+func (qb *queryBuilder) isCteName(tableName string) bool {
+	for _, item := range qb.ctes {
+		if item.name == tableName {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (qb *queryBuilder) resolveCteScopes() error {
+	for _, item := range qb.ctes {
+		if item.qb == nil {
+			continue
+		}
+
+		if err := item.qb.resolveScopes(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (qb *queryBuilder) buildCteStatement() (string, []interface{}) {
+	params := make([]interface{}, 0)
+
+	if len(qb.ctes) < 1 {
+		return "", params
+	}
+
+	parts := make([]string, 0, len(qb.ctes))
+
+	for _, item := range qb.ctes {
+		var query string
+
+		if item.qb != nil {
+			var subParams []interface{}
+			query, subParams = item.qb.buildSelectSql()
+			params = append(params, subParams...)
+		} else {
+			query = item.raw.expr
+			params = append(params, item.raw.bindings...)
+		}
+
+		if query == "" {
+			continue
+		}
+
+		name := quote(item.name)
+
+		if len(item.columns) > 0 {
+			columnNames := make([]string, 0, len(item.columns))
+
+			for _, columnName := range item.columns {
+				columnNames = append(columnNames, quote(columnName))
+			}
+
+			name += "(" + strings.Join(columnNames, ", ") + ")"
+		}
+
+		parts = append(parts, name+" AS ("+query+")")
+	}
+
+	if len(parts) < 1 {
+		return "", params
+	}
+
+	if qb.recursiveCte {
+		return "WITH RECURSIVE " + strings.Join(parts, ", "), params
+	}
+
+	return "WITH " + strings.Join(parts, ", "), params
+}
@@ -0,0 +1,45 @@
+package dbx
+
+import (
+	"testing"
+)
+
+func TestWithCte(t *testing.T) {
+	sub := Table("orders").Select("user_id").Where("status", 1)
+	qb := Table("users").WithCte("paid(user_id)", sub).Where("id", 3)
+	qb.Join("paid", "paid.user_id = users.id")
+	query, params := qb.buildSelectSql()
+	want := "WITH `paid`(`user_id`) AS (SELECT `user_id` FROM `orders` WHERE `status` = ?) " +
+		"SELECT * FROM `users` INNER JOIN `paid` ON paid.user_id = users.id WHERE `id` = ?"
+
+	if query != want {
+		t.Fatalf("sql = %q, want %q", query, want)
+	}
+
+	if len(params) != 2 || params[0] != 1 || params[1] != 3 {
+		t.Fatalf("params = %v, want [1 3]", params)
+	}
+}
+
+func TestWithKeepsRelations(t *testing.T) {
+	qb := Table("orders").With("Items", "Customer", "Items")
+
+	if len(qb.withRelations) != 2 || len(qb.ctes) != 0 {
+		t.Fatalf("relations = %v, ctes = %d", qb.withRelations, len(qb.ctes))
+	}
+}
+
+func TestWithRecursiveCteRaw(t *testing.T) {
+	openSqliteTestDb(t,
+		"CREATE TABLE categories (id INTEGER PRIMARY KEY AUTOINCREMENT, parent_id INTEGER)",
+		"INSERT INTO categories (parent_id) VALUES (NULL), (1), (2), (NULL)",
+	)
+
+	tree := Raw("SELECT id FROM categories WHERE id = ? UNION ALL "+
+		"SELECT c.id FROM categories c INNER JOIN tree t ON c.parent_id = t.id", 1)
+	n, err := Table("tree").WithRecursiveCte("tree(id)", tree).Count()
+
+	if err != nil || n != 3 {
+		t.Fatalf("Count() = %d, %v, want 3", n, err)
+	}
+}
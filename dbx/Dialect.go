@@ -0,0 +1,702 @@
+package dbx
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type Dialect interface {
+	Name() string
+	QuoteIdentifier(name string) string
+	Placeholder(idx int) string
+	LimitClause(offset, count int) string
+	UpsertClause(conflictColumns, updateColumns []string) string
+	SupportsLastInsertId() bool
+	ReturningClause(columnName string) string
+	UnixTimestampExpr(millis bool) string
+	JsonExtractExpr(columnName, path string) (string, []interface{})
+	JsonContainsExpr(columnName, jsonValue string) (string, []interface{})
+	JsonSetExpr(columnName, path, jsonValue string) (string, []interface{})
+	ListTables(db *sql.DB) ([]string, error)
+	DescribeTable(db *sql.DB, tableName string) (*TableSchema, error)
+}
+
+type mysqlDialect struct {
+}
+
+type postgresDialect struct {
+}
+
+type sqliteDialect struct {
+}
+
+func NewMysqlDialect() Dialect {
+	return &mysqlDialect{}
+}
+
+func NewPostgresDialect() Dialect {
+	return &postgresDialect{}
+}
+
+func NewSqliteDialect() Dialect {
+	return &sqliteDialect{}
+}
+
+func DialectByName(name string) Dialect {
+	switch strings.ToLower(name) {
+	case "postgres", "postgresql", "pgx":
+		return NewPostgresDialect()
+	case "sqlite", "sqlite3":
+		return NewSqliteDialect()
+	default:
+		return NewMysqlDialect()
+	}
+}
+
+func (d *mysqlDialect) Name() string {
+	return "mysql"
+}
+
+func (d *mysqlDialect) QuoteIdentifier(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "") + "`"
+}
+
+func (d *mysqlDialect) Placeholder(_ int) string {
+	return "?"
+}
+
+func (d *mysqlDialect) LimitClause(offset, count int) string {
+	if offset > 0 {
+		return fmt.Sprintf("LIMIT %d, %d", offset, count)
+	}
+
+	return fmt.Sprintf("LIMIT %d", count)
+}
+
+func (d *mysqlDialect) UpsertClause(_, updateColumns []string) string {
+	if len(updateColumns) < 1 {
+		return ""
+	}
+
+	sets := make([]string, 0, len(updateColumns))
+
+	for _, columnName := range updateColumns {
+		columnName = d.QuoteIdentifier(columnName)
+		sets = append(sets, fmt.Sprintf("%s = VALUES(%s)", columnName, columnName))
+	}
+
+	return "ON DUPLICATE KEY UPDATE " + strings.Join(sets, ", ")
+}
+
+func (d *mysqlDialect) SupportsLastInsertId() bool {
+	return true
+}
+
+func (d *mysqlDialect) ReturningClause(_ string) string {
+	return ""
+}
+
+func (d *mysqlDialect) UnixTimestampExpr(millis bool) string {
+	if millis {
+		return "ROUND(UNIX_TIMESTAMP(NOW(3)) * 1000)"
+	}
+
+	return "UNIX_TIMESTAMP()"
+}
+
+func (d *mysqlDialect) JsonExtractExpr(columnName, path string) (string, []interface{}) {
+	return fmt.Sprintf("JSON_UNQUOTE(JSON_EXTRACT(%s, ?))", columnName), []interface{}{path}
+}
+
+func (d *mysqlDialect) JsonContainsExpr(columnName, jsonValue string) (string, []interface{}) {
+	return fmt.Sprintf("JSON_CONTAINS(%s, ?)", columnName), []interface{}{jsonValue}
+}
+
+func (d *mysqlDialect) JsonSetExpr(columnName, path, jsonValue string) (string, []interface{}) {
+	expr := fmt.Sprintf("JSON_SET(COALESCE(%s, JSON_OBJECT()), ?, CAST(? AS JSON))", columnName)
+	return expr, []interface{}{path, jsonValue}
+}
+
+func (d *mysqlDialect) ListTables(db *sql.DB) ([]string, error) {
+	query := "SELECT TABLE_NAME FROM information_schema.TABLES WHERE TABLE_SCHEMA = DATABASE() AND TABLE_TYPE = 'BASE TABLE'"
+	return queryStringColumn(db, query)
+}
+
+func (d *mysqlDialect) DescribeTable(db *sql.DB, tableName string) (*TableSchema, error) {
+	schema := &TableSchema{TableName: tableName}
+
+	tables, err := queryRowMaps(
+		db,
+		"SELECT TABLE_COMMENT FROM information_schema.TABLES WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ?",
+		tableName,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(tables) < 1 {
+		return nil, NewDbException("table not found: " + tableName)
+	}
+
+	schema.Comment = tables[0]["TABLE_COMMENT"]
+
+	query := `SELECT COLUMN_NAME, COLUMN_TYPE, IS_NULLABLE, COLUMN_DEFAULT, COLUMN_KEY, EXTRA, COLUMN_COMMENT
+FROM information_schema.COLUMNS
+WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ?
+ORDER BY ORDINAL_POSITION`
+
+	columns, err := queryRowMaps(db, query, tableName)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range columns {
+		fieldType, fieldSize, unsigned := parseColumnType(strings.ToLower(item["COLUMN_TYPE"]))
+
+		schema.Fields = append(schema.Fields, TableFieldInfo{
+			FieldName:     item["COLUMN_NAME"],
+			FieldType:     fieldType,
+			FieldSize:     fieldSize,
+			Unsigned:      unsigned,
+			Nullable:      strings.ToUpper(item["IS_NULLABLE"]) == "YES",
+			DefaultValue:  item["COLUMN_DEFAULT"],
+			AutoIncrement: strings.Contains(item["EXTRA"], "auto_increment"),
+			IsPrimaryKey:  strings.ToUpper(item["COLUMN_KEY"]) == "PRI",
+			Comment:       item["COLUMN_COMMENT"],
+		})
+	}
+
+	query = `SELECT INDEX_NAME, COLUMN_NAME, NON_UNIQUE
+FROM information_schema.STATISTICS
+WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ?
+ORDER BY INDEX_NAME, SEQ_IN_INDEX`
+
+	indexes, err := queryRowMaps(db, query, tableName)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range indexes {
+		indexName := item["INDEX_NAME"]
+		schema.addIndexField(indexName, item["COLUMN_NAME"], item["NON_UNIQUE"] == "0", indexName == "PRIMARY")
+	}
+
+	query = `SELECT k.CONSTRAINT_NAME, k.COLUMN_NAME, k.REFERENCED_TABLE_NAME, k.REFERENCED_COLUMN_NAME,
+r.UPDATE_RULE, r.DELETE_RULE
+FROM information_schema.KEY_COLUMN_USAGE k
+JOIN information_schema.REFERENTIAL_CONSTRAINTS r
+ON r.CONSTRAINT_SCHEMA = k.CONSTRAINT_SCHEMA AND r.CONSTRAINT_NAME = k.CONSTRAINT_NAME
+WHERE k.TABLE_SCHEMA = DATABASE() AND k.TABLE_NAME = ?
+ORDER BY k.CONSTRAINT_NAME, k.ORDINAL_POSITION`
+
+	foreignKeys, err := queryRowMaps(db, query, tableName)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range foreignKeys {
+		schema.addForeignKeyField(
+			item["CONSTRAINT_NAME"],
+			item["COLUMN_NAME"],
+			item["REFERENCED_TABLE_NAME"],
+			item["REFERENCED_COLUMN_NAME"],
+			item["UPDATE_RULE"],
+			item["DELETE_RULE"],
+		)
+	}
+
+	return schema, nil
+}
+
+func (d *postgresDialect) Name() string {
+	return "postgres"
+}
+
+func (d *postgresDialect) QuoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, "") + `"`
+}
+
+func (d *postgresDialect) Placeholder(idx int) string {
+	return "$" + strconv.Itoa(idx)
+}
+
+func (d *postgresDialect) LimitClause(offset, count int) string {
+	if offset > 0 {
+		return fmt.Sprintf("LIMIT %d OFFSET %d", count, offset)
+	}
+
+	return fmt.Sprintf("LIMIT %d", count)
+}
+
+func (d *postgresDialect) UpsertClause(conflictColumns, updateColumns []string) string {
+	return buildOnConflictClause(d, conflictColumns, updateColumns)
+}
+
+func (d *postgresDialect) SupportsLastInsertId() bool {
+	return false
+}
+
+func (d *postgresDialect) ReturningClause(columnName string) string {
+	if columnName == "" {
+		return ""
+	}
+
+	return "RETURNING " + d.QuoteIdentifier(columnName)
+}
+
+func (d *postgresDialect) UnixTimestampExpr(millis bool) string {
+	if millis {
+		return "CAST(EXTRACT(EPOCH FROM NOW()) * 1000 AS BIGINT)"
+	}
+
+	return "CAST(EXTRACT(EPOCH FROM NOW()) AS BIGINT)"
+}
+
+func (d *postgresDialect) JsonExtractExpr(columnName, path string) (string, []interface{}) {
+	return fmt.Sprintf("(%s::jsonb #>> ?::text[])", columnName), []interface{}{toPostgresJsonPath(path)}
+}
+
+func (d *postgresDialect) JsonContainsExpr(columnName, jsonValue string) (string, []interface{}) {
+	return fmt.Sprintf("%s::jsonb @> ?::jsonb", columnName), []interface{}{jsonValue}
+}
+
+func (d *postgresDialect) JsonSetExpr(columnName, path, jsonValue string) (string, []interface{}) {
+	expr := fmt.Sprintf("jsonb_set(COALESCE(%s::jsonb, '{}'::jsonb), ?::text[], ?::jsonb)", columnName)
+	return expr, []interface{}{toPostgresJsonPath(path), jsonValue}
+}
+
+func (d *postgresDialect) ListTables(db *sql.DB) ([]string, error) {
+	query := "SELECT table_name FROM information_schema.tables WHERE table_schema = current_schema() AND table_type = 'BASE TABLE'"
+	return queryStringColumn(db, query)
+}
+
+func (d *postgresDialect) DescribeTable(db *sql.DB, tableName string) (*TableSchema, error) {
+	schema := &TableSchema{TableName: tableName}
+
+	query := `SELECT COALESCE(obj_description(c.oid, 'pg_class'), '') AS table_comment
+FROM pg_class c
+JOIN pg_namespace n ON n.oid = c.relnamespace
+WHERE n.nspname = current_schema() AND c.relname = $1 AND c.relkind IN ('r', 'p')`
+
+	tables, err := queryRowMaps(db, query, tableName)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(tables) < 1 {
+		return nil, NewDbException("table not found: " + tableName)
+	}
+
+	schema.Comment = tables[0]["table_comment"]
+
+	query = `SELECT c.column_name, c.data_type,
+COALESCE(c.character_maximum_length, c.numeric_precision, 0) AS column_size,
+c.is_nullable, COALESCE(c.column_default, '') AS column_default,
+COALESCE(col_description(format('%I.%I', c.table_schema, c.table_name)::regclass, c.ordinal_position), '') AS column_comment
+FROM information_schema.columns c
+WHERE c.table_schema = current_schema() AND c.table_name = $1
+ORDER BY c.ordinal_position`
+
+	columns, err := queryRowMaps(db, query, tableName)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range columns {
+		defaultValue := item["column_default"]
+
+		schema.Fields = append(schema.Fields, TableFieldInfo{
+			FieldName:     item["column_name"],
+			FieldType:     normalizePostgresType(item["data_type"]),
+			FieldSize:     toInt(item["column_size"]),
+			Nullable:      strings.ToUpper(item["is_nullable"]) == "YES",
+			DefaultValue:  defaultValue,
+			AutoIncrement: strings.HasPrefix(defaultValue, "nextval("),
+			Comment:       item["column_comment"],
+		})
+	}
+
+	query = `SELECT i.relname AS index_name, a.attname AS column_name,
+ix.indisunique AS is_unique, ix.indisprimary AS is_primary
+FROM pg_class t
+JOIN pg_namespace n ON n.oid = t.relnamespace
+JOIN pg_index ix ON ix.indrelid = t.oid
+JOIN pg_class i ON i.oid = ix.indexrelid
+JOIN LATERAL unnest(ix.indkey) WITH ORDINALITY AS k(attnum, ord) ON true
+JOIN pg_attribute a ON a.attrelid = t.oid AND a.attnum = k.attnum
+WHERE n.nspname = current_schema() AND t.relname = $1
+ORDER BY i.relname, k.ord`
+
+	indexes, err := queryRowMaps(db, query, tableName)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range indexes {
+		isPrimary := isTruthy(item["is_primary"])
+		schema.addIndexField(item["index_name"], item["column_name"], isTruthy(item["is_unique"]), isPrimary)
+
+		if isPrimary {
+			schema.markPrimaryKey(item["column_name"])
+		}
+	}
+
+	query = `SELECT tc.constraint_name, kcu.column_name,
+ccu.table_name AS ref_table_name, ccu.column_name AS ref_column_name,
+rc.update_rule, rc.delete_rule
+FROM information_schema.table_constraints tc
+JOIN information_schema.key_column_usage kcu
+ON kcu.constraint_schema = tc.constraint_schema AND kcu.constraint_name = tc.constraint_name
+JOIN information_schema.referential_constraints rc
+ON rc.constraint_schema = tc.constraint_schema AND rc.constraint_name = tc.constraint_name
+JOIN information_schema.key_column_usage ccu
+ON ccu.constraint_schema = rc.unique_constraint_schema AND ccu.constraint_name = rc.unique_constraint_name
+AND ccu.ordinal_position = kcu.position_in_unique_constraint
+WHERE tc.constraint_type = 'FOREIGN KEY' AND tc.table_schema = current_schema() AND tc.table_name = $1
+ORDER BY tc.constraint_name, kcu.ordinal_position`
+
+	foreignKeys, err := queryRowMaps(db, query, tableName)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range foreignKeys {
+		schema.addForeignKeyField(
+			item["constraint_name"],
+			item["column_name"],
+			item["ref_table_name"],
+			item["ref_column_name"],
+			item["update_rule"],
+			item["delete_rule"],
+		)
+	}
+
+	return schema, nil
+}
+
+func (d *sqliteDialect) Name() string {
+	return "sqlite"
+}
+
+func (d *sqliteDialect) QuoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, "") + `"`
+}
+
+func (d *sqliteDialect) Placeholder(_ int) string {
+	return "?"
+}
+
+func (d *sqliteDialect) LimitClause(offset, count int) string {
+	if offset > 0 {
+		return fmt.Sprintf("LIMIT %d OFFSET %d", count, offset)
+	}
+
+	return fmt.Sprintf("LIMIT %d", count)
+}
+
+func (d *sqliteDialect) UpsertClause(conflictColumns, updateColumns []string) string {
+	return buildOnConflictClause(d, conflictColumns, updateColumns)
+}
+
+func (d *sqliteDialect) SupportsLastInsertId() bool {
+	return true
+}
+
+func (d *sqliteDialect) ReturningClause(_ string) string {
+	return ""
+}
+
+func (d *sqliteDialect) UnixTimestampExpr(millis bool) string {
+	if millis {
+		return "CAST((julianday('now') - 2440587.5) * 86400000 AS INTEGER)"
+	}
+
+	return "CAST(strftime('%s', 'now') AS INTEGER)"
+}
+
+func (d *sqliteDialect) JsonExtractExpr(columnName, path string) (string, []interface{}) {
+	return fmt.Sprintf("json_extract(%s, ?)", columnName), []interface{}{path}
+}
+
+// JsonContainsExpr matches when jsonValue, or every element of it when it is an array, is a member
+// of the column. Unlike JSON_CONTAINS and @>, objects match whole members only, not key subsets.
+func (d *sqliteDialect) JsonContainsExpr(columnName, jsonValue string) (string, []interface{}) {
+	candidates := "CASE json_type(?) WHEN 'array' THEN ? ELSE json_array(json(?)) END"
+	expr := fmt.Sprintf("NOT EXISTS (SELECT 1 FROM json_each(%s) AS c WHERE NOT EXISTS ", candidates) +
+		fmt.Sprintf("(SELECT 1 FROM json_each(%s) AS t WHERE t.value = c.value))", columnName)
+	return expr, []interface{}{jsonValue, jsonValue, jsonValue}
+}
+
+func (d *sqliteDialect) JsonSetExpr(columnName, path, jsonValue string) (string, []interface{}) {
+	return fmt.Sprintf("json_set(COALESCE(%s, '{}'), ?, json(?))", columnName), []interface{}{path, jsonValue}
+}
+
+func (d *sqliteDialect) ListTables(db *sql.DB) ([]string, error) {
+	query := "SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%'"
+	return queryStringColumn(db, query)
+}
+
+func (d *sqliteDialect) DescribeTable(db *sql.DB, tableName string) (*TableSchema, error) {
+	schema := &TableSchema{TableName: tableName}
+	quotedName := d.QuoteIdentifier(tableName)
+	columns, err := queryRowMaps(db, fmt.Sprintf("PRAGMA table_info(%s)", quotedName))
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(columns) < 1 {
+		return nil, NewDbException("table not found: " + tableName)
+	}
+
+	for _, item := range columns {
+		fieldType, fieldSize, unsigned := parseColumnType(strings.ToLower(item["type"]))
+		isPrimaryKey := toInt(item["pk"]) > 0
+
+		schema.Fields = append(schema.Fields, TableFieldInfo{
+			FieldName:     item["name"],
+			FieldType:     fieldType,
+			FieldSize:     fieldSize,
+			Unsigned:      unsigned,
+			Nullable:      item["notnull"] == "0" && !isPrimaryKey,
+			DefaultValue:  item["dflt_value"],
+			AutoIncrement: isPrimaryKey && fieldType == "integer",
+			IsPrimaryKey:  isPrimaryKey,
+		})
+	}
+
+	indexes, err := queryRowMaps(db, fmt.Sprintf("PRAGMA index_list(%s)", quotedName))
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, index := range indexes {
+		indexName := index["name"]
+		isUnique := index["unique"] == "1"
+		isPrimary := index["origin"] == "pk"
+		indexColumns, err := queryRowMaps(db, fmt.Sprintf("PRAGMA index_info(%s)", d.QuoteIdentifier(indexName)))
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, item := range indexColumns {
+			schema.addIndexField(indexName, item["name"], isUnique, isPrimary)
+		}
+	}
+
+	foreignKeys, err := queryRowMaps(db, fmt.Sprintf("PRAGMA foreign_key_list(%s)", quotedName))
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range foreignKeys {
+		schema.addForeignKeyField(
+			fmt.Sprintf("fk_%s_%s", tableName, item["id"]),
+			item["from"],
+			item["table"],
+			item["to"],
+			item["on_update"],
+			item["on_delete"],
+		)
+	}
+
+	return schema, nil
+}
+
+func buildOnConflictClause(d Dialect, conflictColumns, updateColumns []string) string {
+	if len(conflictColumns) < 1 {
+		return ""
+	}
+
+	targets := make([]string, 0, len(conflictColumns))
+
+	for _, columnName := range conflictColumns {
+		targets = append(targets, d.QuoteIdentifier(columnName))
+	}
+
+	if len(updateColumns) < 1 {
+		return fmt.Sprintf("ON CONFLICT (%s) DO NOTHING", strings.Join(targets, ", "))
+	}
+
+	sets := make([]string, 0, len(updateColumns))
+
+	for _, columnName := range updateColumns {
+		columnName = d.QuoteIdentifier(columnName)
+		sets = append(sets, fmt.Sprintf("%s = EXCLUDED.%s", columnName, columnName))
+	}
+
+	return fmt.Sprintf("ON CONFLICT (%s) DO UPDATE SET %s", strings.Join(targets, ", "), strings.Join(sets, ", "))
+}
+
+func queryStringColumn(db *sql.DB, query string, args ...interface{}) ([]string, error) {
+	rows, err := db.Query(query, args...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+	list := make([]string, 0)
+
+	for rows.Next() {
+		var s1 sql.NullString
+
+		if err := rows.Scan(&s1); err != nil {
+			return nil, err
+		}
+
+		if s1.String != "" {
+			list = append(list, s1.String)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+func queryRowMaps(db *sql.DB, query string, args ...interface{}) ([]map[string]string, error) {
+	rows, err := db.Query(query, args...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+	columnNames, err := rows.Columns()
+
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]map[string]string, 0)
+
+	for rows.Next() {
+		values := make([]sql.NullString, len(columnNames))
+		scanArgs := make([]interface{}, len(columnNames))
+
+		for idx := range values {
+			scanArgs[idx] = &values[idx]
+		}
+
+		if err := rows.Scan(scanArgs...); err != nil {
+			return nil, err
+		}
+
+		item := map[string]string{}
+
+		for idx, columnName := range columnNames {
+			item[columnName] = values[idx].String
+		}
+
+		list = append(list, item)
+	}
+
+	return list, rows.Err()
+}
+
+func parseColumnType(columnType string) (fieldType string, fieldSize int, unsigned bool) {
+	unsigned = strings.Contains(columnType, "unsigned")
+
+	if strings.Contains(columnType, " ") {
+		fieldType = substringBefore(columnType, " ")
+	} else {
+		fieldType = columnType
+	}
+
+	if strings.HasSuffix(fieldType, ")") {
+		s1 := strings.TrimSuffix(substringAfter(fieldType, "("), ")")
+
+		if strings.Contains(s1, ",") {
+			s1 = substringBefore(s1, ",")
+		}
+
+		fieldSize = toInt(s1)
+		fieldType = substringBefore(fieldType, "(")
+	}
+
+	return
+}
+
+func normalizePostgresType(dataType string) string {
+	switch dataType {
+	case "timestamp without time zone", "timestamp with time zone":
+		return "timestamp"
+	case "character varying":
+		return "varchar"
+	case "character":
+		return "char"
+	case "double precision":
+		return "double"
+	}
+
+	return dataType
+}
+
+// rebind numbers the ? placeholders outside of quoted literals and identifiers. A backslash escapes
+// the next character in a string literal, except in a plain PostgreSQL literal, where backslashes only
+// count inside E'...' strings.
+func rebind(query string) string {
+	d := getDialect()
+
+	if d.Placeholder(1) == "?" || !strings.Contains(query, "?") {
+		return query
+	}
+
+	sb := strings.Builder{}
+	var quoteChar, prevChar rune
+	var escapes, escaped bool
+	n1 := 0
+
+	for _, ch := range query {
+		if quoteChar != 0 {
+			switch {
+			case escaped:
+				escaped = false
+			case ch == '\\' && escapes:
+				escaped = true
+			case ch == quoteChar:
+				quoteChar = 0
+			}
+
+			sb.WriteRune(ch)
+			prevChar = ch
+			continue
+		}
+
+		switch ch {
+		case '\'':
+			quoteChar = ch
+			escapes = d.Name() != "postgres" || prevChar == 'E' || prevChar == 'e'
+			sb.WriteRune(ch)
+		case '"', '`':
+			quoteChar = ch
+			escapes = false
+			sb.WriteRune(ch)
+		case '?':
+			n1++
+			sb.WriteString(d.Placeholder(n1))
+		default:
+			sb.WriteRune(ch)
+		}
+
+		prevChar = ch
+	}
+
+	return sb.String()
+}
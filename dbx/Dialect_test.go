@@ -0,0 +1,105 @@
+package dbx
+
+import (
+	"context"
+	"testing"
+)
+
+func TestRebindPostgres(t *testing.T) {
+	dialect = NewPostgresDialect()
+	defer func() { dialect = nil }()
+
+	cases := []struct {
+		query string
+		want  string
+	}{
+		{"SELECT * FROM t WHERE a = ? AND b = ?", "SELECT * FROM t WHERE a = $1 AND b = $2"},
+		{"SELECT '?' AS q, \"x?\" FROM t WHERE a = ?", "SELECT '?' AS q, \"x?\" FROM t WHERE a = $1"},
+		{"SELECT 'it''s ?' FROM t WHERE a = ?", "SELECT 'it''s ?' FROM t WHERE a = $1"},
+		{`SELECT E'it\'s ?' FROM t WHERE a = ?`, `SELECT E'it\'s ?' FROM t WHERE a = $1`},
+		{`SELECT 'C:\' FROM t WHERE a = ?`, `SELECT 'C:\' FROM t WHERE a = $1`},
+	}
+
+	for _, c := range cases {
+		if got := rebind(c.query); got != c.want {
+			t.Errorf("rebind(%q) = %q, want %q", c.query, got, c.want)
+		}
+	}
+}
+
+func TestDialectClauses(t *testing.T) {
+	cases := []struct {
+		d      Dialect
+		quoted string
+		limit  string
+		upsert string
+	}{
+		{NewMysqlDialect(), "`users`", "LIMIT 20, 10", "ON DUPLICATE KEY UPDATE `name` = VALUES(`name`)"},
+		{NewPostgresDialect(), `"users"`, "LIMIT 10 OFFSET 20", `ON CONFLICT ("id") DO UPDATE SET "name" = EXCLUDED."name"`},
+		{NewSqliteDialect(), `"users"`, "LIMIT 10 OFFSET 20", `ON CONFLICT ("id") DO UPDATE SET "name" = EXCLUDED."name"`},
+	}
+
+	for _, c := range cases {
+		if got := c.d.QuoteIdentifier("users"); got != c.quoted {
+			t.Errorf("%s QuoteIdentifier = %q, want %q", c.d.Name(), got, c.quoted)
+		}
+
+		if got := c.d.LimitClause(20, 10); got != c.limit {
+			t.Errorf("%s LimitClause = %q, want %q", c.d.Name(), got, c.limit)
+		}
+
+		if got := c.d.UpsertClause([]string{"id"}, []string{"name"}); got != c.upsert {
+			t.Errorf("%s UpsertClause = %q, want %q", c.d.Name(), got, c.upsert)
+		}
+	}
+}
+
+func TestSqliteRepositoryRoundTrip(t *testing.T) {
+	openSqliteTestDb(t, "CREATE TABLE users (id INTEGER PRIMARY KEY AUTOINCREMENT, email TEXT NOT NULL UNIQUE, name TEXT)")
+
+	schema, ok := GetTableSchema("users")
+
+	if !ok || len(schema.Fields) != 3 || !schema.Fields[0].IsPrimaryKey {
+		t.Fatalf("schema = %+v, %v", schema, ok)
+	}
+
+	id, err := Table("users").Insert(map[string]interface{}{"email": "a@x.io", "name": "a"})
+
+	if err != nil || id != 1 {
+		t.Fatalf("Insert() = %d, %v, want 1", id, err)
+	}
+
+	if _, err := Table("users").Upsert(map[string]interface{}{"email": "a@x.io", "name": "b"}, "email"); err != nil {
+		t.Fatal(err)
+	}
+
+	if name, err := Table("users").Where("id", id).Value("name"); err != nil || name != "b" {
+		t.Fatalf("Value(name) = %v, %v, want b", name, err)
+	}
+
+	if n, err := Table("users").Where("id", id).Update(map[string]interface{}{"name": "c"}); err != nil || n != 1 {
+		t.Fatalf("Update() = %d, %v, want 1", n, err)
+	}
+
+	if n, err := Table("users").Where("name", "c").Delete(); err != nil || n != 1 {
+		t.Fatalf("Delete() = %d, %v, want 1", n, err)
+	}
+
+	if n, err := Table("users").Count(); err != nil || n != 0 {
+		t.Fatalf("Count() = %d, %v, want 0", n, err)
+	}
+}
+
+func TestInsertReturningSkippedRow(t *testing.T) {
+	openSqliteTestDb(t,
+		"CREATE TABLE tags (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT NOT NULL UNIQUE)",
+		"INSERT INTO tags (name) VALUES ('go')",
+	)
+
+	query := "INSERT INTO tags (name) VALUES (?) ON CONFLICT (name) DO NOTHING RETURNING id"
+	n, err := doInsertReturningBySql(context.Background(), nil, query, []interface{}{"go"})
+
+	if err != nil || n != 0 {
+		t.Fatalf("doInsertReturningBySql() = %d, %v, want 0, nil", n, err)
+	}
+}
@@ -0,0 +1,252 @@
+package dbx
+
+import (
+	"fmt"
+	"github.com/meiguonet/mgboot-go-common/util/castx"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	FilterEq        = "eq"
+	FilterNe        = "ne"
+	FilterGt        = "gt"
+	FilterGte       = "gte"
+	FilterLt        = "lt"
+	FilterLte       = "lte"
+	FilterLike      = "like"
+	FilterIn        = "in"
+	FilterBetween   = "between"
+	FilterDateRange = "date_range"
+)
+
+func (qb *queryBuilder) When(cond bool, fn func(q *queryBuilder), otherwise ...func(q *queryBuilder)) *queryBuilder {
+	if cond {
+		if fn != nil {
+			fn(qb)
+		}
+
+		return qb
+	}
+
+	if len(otherwise) > 0 && otherwise[0] != nil {
+		otherwise[0](qb)
+	}
+
+	return qb
+}
+
+func (qb *queryBuilder) Unless(cond bool, fn func(q *queryBuilder), otherwise ...func(q *queryBuilder)) *queryBuilder {
+	return qb.When(!cond, fn, otherwise...)
+}
+
+func (qb *queryBuilder) Filter(params map[string]interface{}, rules map[string]string) *queryBuilder {
+	if len(params) < 1 || len(rules) < 1 {
+		return qb
+	}
+
+	keys := make([]string, 0, len(rules))
+
+	for key := range rules {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value, ok := params[key]
+
+		if !ok || isEmptyFilterValue(value) {
+			continue
+		}
+
+		operator, columnName := parseFilterRule(key, rules[key])
+
+		if !isValidIdentifier(columnName, false) {
+			qb.setErr(newInvalidIdentifierException("column", columnName))
+			continue
+		}
+
+		qb.applyFilter(columnName, operator, value)
+	}
+
+	return qb
+}
+
+func parseFilterRule(key, rule string) (operator, columnName string) {
+	rule = strings.TrimSpace(rule)
+	operator = rule
+	columnName = key
+
+	if strings.Contains(rule, ":") {
+		operator = strings.TrimSpace(substringBefore(rule, ":"))
+		columnName = strings.TrimSpace(substringAfter(rule, ":"))
+	}
+
+	return strings.ToLower(operator), columnName
+}
+
+var likeEscaper = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
+
+func (qb *queryBuilder) applyFilter(columnName, operator string, value interface{}) {
+	if isListValue(indirect(value)) {
+		switch operator {
+		case FilterEq, "":
+			qb.WhereIn(columnName, toSlice(indirect(value)))
+			return
+		case FilterNe:
+			qb.WhereNotIn(columnName, toSlice(indirect(value)))
+			return
+		case FilterIn, FilterBetween, FilterDateRange:
+		default:
+			qb.setErr(NewDbException(fmt.Sprintf("filter operator %q on column [%s] takes a single value", operator, columnName)))
+			return
+		}
+	}
+
+	switch operator {
+	case FilterEq, "":
+		qb.Where(columnName, value)
+	case FilterNe:
+		qb.Where(columnName, "<>", value)
+	case FilterGt:
+		qb.Where(columnName, ">", value)
+	case FilterGte:
+		qb.Where(columnName, ">=", value)
+	case FilterLt:
+		qb.Where(columnName, "<", value)
+	case FilterLte:
+		qb.Where(columnName, "<=", value)
+	case FilterLike:
+		// user input is matched literally, so % and _ in it are not wildcards
+		qb.addCondition(quote(columnName) + " LIKE ? ESCAPE '!'")
+		qb.addBindValues("%" + likeEscaper.Replace(castx.ToString(value)) + "%")
+	case FilterIn:
+		if values := toFilterValues(value); len(values) > 0 {
+			qb.WhereIn(columnName, values)
+		}
+	case FilterBetween:
+		start, end := toFilterRange(value)
+
+		switch {
+		case start != nil && end != nil:
+			qb.WhereBetween(columnName, start, end)
+		case start != nil:
+			qb.Where(columnName, ">=", start)
+		case end != nil:
+			qb.Where(columnName, "<=", end)
+		}
+	case FilterDateRange:
+		qb.applyDateRangeFilter(columnName, value)
+	default:
+		qb.setErr(NewDbException(fmt.Sprintf("unsupported filter operator: %q", operator)))
+	}
+}
+
+func (qb *queryBuilder) applyDateRangeFilter(columnName string, value interface{}) {
+	start, end := toFilterRange(value)
+	loc := qb.timeLocation(columnName)
+	from, ok1 := toFilterDate(start, loc)
+	to, ok2 := toFilterDate(end, loc)
+
+	for _, bound := range []struct {
+		value interface{}
+		ok    bool
+	}{{start, ok1}, {end, ok2}} {
+		if bound.value != nil && !bound.ok {
+			qb.setErr(NewDbException(fmt.Sprintf("invalid date_range bound %v of column [%s]", bound.value, columnName)))
+			return
+		}
+	}
+
+	if ok2 {
+		to = time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, loc).AddDate(0, 0, 1)
+	}
+
+	switch {
+	case ok1 && ok2:
+		qb.WhereTimeBetween(columnName, from, to)
+	case ok1:
+		qb.Where(columnName, ">=", qb.timeBindValue(columnName, from))
+	case ok2:
+		qb.Where(columnName, "<", qb.timeBindValue(columnName, to))
+	}
+}
+
+func isEmptyFilterValue(value interface{}) bool {
+	value = indirect(value)
+
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return strings.TrimSpace(v) == ""
+	case time.Time:
+		return v.IsZero()
+	case []string:
+		return len(v) < 1
+	case []interface{}:
+		return len(v) < 1
+	}
+
+	return false
+}
+
+func toFilterValues(value interface{}) []interface{} {
+	value = indirect(value)
+
+	if s1, ok := value.(string); ok {
+		values := make([]interface{}, 0)
+
+		for _, item := range regexpCommaSep.Split(strings.TrimSpace(s1), -1) {
+			if item != "" {
+				values = append(values, item)
+			}
+		}
+
+		return values
+	}
+
+	if value == nil {
+		return nil
+	}
+
+	return toSlice(value)
+}
+
+func toFilterRange(value interface{}) (start, end interface{}) {
+	values := toFilterValues(value)
+
+	if s1, ok := indirect(value).(string); ok && strings.Contains(s1, ",") {
+		parts := regexpCommaSep.Split(strings.TrimSpace(s1), -1)
+		values = []interface{}{parts[0], parts[len(parts)-1]}
+	}
+
+	if len(values) > 0 && !isEmptyFilterValue(values[0]) {
+		start = values[0]
+	}
+
+	if len(values) > 1 && !isEmptyFilterValue(values[1]) {
+		end = values[1]
+	}
+
+	return
+}
+
+func toFilterDate(value interface{}, loc *time.Location) (time.Time, bool) {
+	switch v := indirect(value).(type) {
+	case time.Time:
+		return v.In(loc), !v.IsZero()
+	case string:
+		s1 := strings.ReplaceAll(strings.TrimSpace(v), "/", "-")
+
+		for _, layout := range []string{dateFormatFull, dateFormatDateOnly} {
+			if t1, err := time.ParseInLocation(layout, s1, loc); err == nil {
+				return t1, true
+			}
+		}
+	}
+
+	return time.Time{}, false
+}
@@ -0,0 +1,101 @@
+package dbx
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseFilterRule(t *testing.T) {
+	cases := []struct {
+		key, rule        string
+		operator, column string
+	}{
+		{"name", "like", "like", "name"},
+		{"kw", "LIKE:title", "like", "title"},
+		{"created", " date_range : created_at ", "date_range", "created_at"},
+		{"status", "", "", "status"},
+	}
+
+	for _, c := range cases {
+		operator, column := parseFilterRule(c.key, c.rule)
+
+		if operator != c.operator || column != c.column {
+			t.Errorf("parseFilterRule(%q, %q) = %q, %q, want %q, %q", c.key, c.rule, operator, column, c.operator, c.column)
+		}
+	}
+}
+
+func TestFilter(t *testing.T) {
+	params := map[string]interface{}{
+		"status":  []int{1, 2},
+		"kw":      "50%_off",
+		"price":   "10,20",
+		"skipped": "",
+		"user_id": 7,
+	}
+
+	rules := map[string]string{
+		"status":  "eq",
+		"kw":      "like:title",
+		"price":   "between",
+		"skipped": "eq",
+		"user_id": "ne",
+	}
+
+	qb := Table("products").Filter(params, rules)
+
+	if err := qb.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	query, bindings := qb.buildSelectSql()
+	want := "SELECT * FROM `products` WHERE `title` LIKE ? ESCAPE '!' AND `price` BETWEEN ? AND ? " +
+		"AND `status` IN (?, ?) AND `user_id` <> ?"
+
+	if query != want {
+		t.Fatalf("sql = %q, want %q", query, want)
+	}
+
+	wantBindings := []interface{}{"%50!%!_off%", "10", "20", 1, 2, 7}
+
+	if !reflect.DeepEqual(bindings, wantBindings) {
+		t.Fatalf("bindings = %#v, want %#v", bindings, wantBindings)
+	}
+}
+
+func TestFilterRejects(t *testing.T) {
+	if err := Table("products").Filter(map[string]interface{}{"price": []int{1, 2}}, map[string]string{"price": "gt"}).Err(); err == nil {
+		t.Fatal("gt with a list value accepted")
+	}
+
+	if err := Table("products").Filter(map[string]interface{}{"a": 1}, map[string]string{"a": "eq:a;drop"}).Err(); err == nil {
+		t.Fatal("invalid column accepted")
+	}
+
+	if err := Table("products").Filter(map[string]interface{}{"a": 1}, map[string]string{"a": "approx"}).Err(); err == nil {
+		t.Fatal("unknown operator accepted")
+	}
+
+	for _, value := range []string{"2024-13-01,2024-12-31", "2024-01-01,yesterday", "soon"} {
+		qb := Table("orders").Filter(map[string]interface{}{"created": value}, map[string]string{"created": "date_range:created_at"})
+
+		if qb.Err() == nil {
+			t.Errorf("date_range %q accepted", value)
+		}
+	}
+}
+
+func TestFilterLikeMatchesLiterally(t *testing.T) {
+	openSqliteTestDb(t,
+		"CREATE TABLE products (id INTEGER PRIMARY KEY AUTOINCREMENT, title TEXT)",
+		"INSERT INTO products (title) VALUES ('50% off'), ('500 off'), ('a_b'), ('axb')",
+	)
+
+	for kw, want := range map[string]int{"50%": 1, "a_b": 1, "off": 2} {
+		n, err := Table("products").Filter(map[string]interface{}{"kw": kw}, map[string]string{"kw": "like:title"}).Count()
+
+		if err != nil || n != want {
+			t.Fatalf("like %q: Count() = %d, %v, want %d", kw, n, err, want)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package dbx
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	MatchNaturalLanguage = "NATURAL LANGUAGE"
+	MatchBoolean         = "BOOLEAN"
+	MatchQueryExpansion  = "QUERY EXPANSION"
+)
+
+var fullTextOperatorReplacer = strings.NewReplacer(
+	"+", " ", "-", " ", "<", " ", ">", " ", "(", " ", ")", " ",
+	"~", " ", "*", " ", `"`, " ", "@", " ",
+)
+
+func (qb *queryBuilder) WhereMatch(columns interface{}, query, mode string) *queryBuilder {
+	expr, params, err := buildMatchExpr(columns, query, mode)
+
+	if err != nil {
+		return qb.setErr(err)
+	}
+
+	qb.addCondition(expr)
+	qb.addBindValues(params...)
+	return qb
+}
+
+func (qb *queryBuilder) OrWhereMatch(columns interface{}, query, mode string) *queryBuilder {
+	expr, params, err := buildMatchExpr(columns, query, mode)
+
+	if err != nil {
+		return qb.setErr(err)
+	}
+
+	qb.addCondition(expr, true)
+	qb.addBindValues(params...)
+	return qb
+}
+
+func (qb *queryBuilder) SelectMatchScore(columns interface{}, query, mode, alias string, orderByScore ...bool) *queryBuilder {
+	if !isValidAlias(alias) {
+		return qb.setErr(newInvalidIdentifierException("alias", alias))
+	}
+
+	expr, params, err := buildMatchExpr(columns, query, mode)
+
+	if err != nil {
+		return qb.setErr(err)
+	}
+
+	if len(qb.columns) < 1 {
+		qb.addColumn("*")
+	}
+
+	qb.addRawColumn(Raw(expr+" AS "+quote(alias), params...))
+
+	if len(orderByScore) > 0 && orderByScore[0] {
+		qb.addOrderBy(alias + " DESC")
+	}
+
+	return qb
+}
+
+func buildMatchExpr(columns interface{}, query, mode string) (string, []interface{}, error) {
+	if getDialect().Name() != "mysql" {
+		return "", nil, NewDbException("full-text MATCH ... AGAINST is only supported by mysql")
+	}
+
+	var columnNames []string
+
+	if a1, ok := columns.([]string); ok {
+		columnNames = a1
+	} else if s1, ok := columns.(string); ok && s1 != "" {
+		columnNames = regexpCommaSep.Split(strings.TrimSpace(s1), -1)
+	}
+
+	if len(columnNames) < 1 {
+		return "", nil, NewDbException("full-text MATCH requires at least one column")
+	}
+
+	quoted := make([]string, 0, len(columnNames))
+
+	for _, columnName := range columnNames {
+		if !isValidIdentifier(columnName, false) {
+			return "", nil, newInvalidIdentifierException("column", columnName)
+		}
+
+		quoted = append(quoted, quote(columnName))
+	}
+
+	modifier, err := parseMatchMode(mode)
+
+	if err != nil {
+		return "", nil, err
+	}
+
+	if modifier == "IN BOOLEAN MODE" {
+		query = escapeBooleanQuery(query)
+	}
+
+	expr := fmt.Sprintf("MATCH (%s) AGAINST (? %s)", strings.Join(quoted, ", "), modifier)
+	return expr, []interface{}{query}, nil
+}
+
+func parseMatchMode(mode string) (string, error) {
+	mode = strings.ToUpper(regexpSpace.ReplaceAllString(strings.TrimSpace(mode), " "))
+	mode = strings.TrimPrefix(mode, "IN ")
+
+	switch mode {
+	case "", MatchNaturalLanguage, MatchNaturalLanguage + " MODE":
+		return "IN NATURAL LANGUAGE MODE", nil
+	case MatchBoolean, MatchBoolean + " MODE":
+		return "IN BOOLEAN MODE", nil
+	case MatchQueryExpansion, "WITH " + MatchQueryExpansion, MatchNaturalLanguage + " MODE WITH " + MatchQueryExpansion:
+		return "WITH QUERY EXPANSION", nil
+	}
+
+	return "", NewDbException(fmt.Sprintf("invalid full-text match mode: %q", mode))
+}
+
+// escapeBooleanQuery keeps a leading + or - on each term and drops every other boolean operator,
+// so callers can require or exclude words without being able to inject groups, phrases or wildcards.
+func escapeBooleanQuery(query string) string {
+	terms := make([]string, 0)
+
+	for _, field := range strings.Fields(query) {
+		prefix := ""
+
+		if strings.HasPrefix(field, "+") || strings.HasPrefix(field, "-") {
+			prefix = field[:1]
+		}
+
+		for _, word := range strings.Fields(fullTextOperatorReplacer.Replace(field)) {
+			terms = append(terms, prefix+word)
+		}
+	}
+
+	return strings.Join(terms, " ")
+}
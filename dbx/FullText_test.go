@@ -0,0 +1,66 @@
+package dbx
+
+import (
+	"testing"
+)
+
+func TestMatchModes(t *testing.T) {
+	cases := []struct {
+		mode, query, wantModifier, wantQuery string
+	}{
+		{"", "go sql", "IN NATURAL LANGUAGE MODE", "go sql"},
+		{MatchQueryExpansion, "go", "WITH QUERY EXPANSION", "go"},
+		{"in boolean mode", `+go -java "orm*" (x) ~y`, "IN BOOLEAN MODE", "+go -java orm x y"},
+		{MatchBoolean, "+go-sql --rust +-", "IN BOOLEAN MODE", "+go +sql -rust"},
+	}
+
+	for _, c := range cases {
+		query, params := Table("posts").WhereMatch("title, body", c.query, c.mode).buildSelectSql()
+		want := "SELECT * FROM `posts` WHERE MATCH (`title`, `body`) AGAINST (? " + c.wantModifier + ")"
+
+		if query != want {
+			t.Errorf("mode %q: sql = %q, want %q", c.mode, query, want)
+		}
+
+		if len(params) != 1 || params[0] != c.wantQuery {
+			t.Errorf("mode %q: params = %q, want [%q]", c.mode, params, c.wantQuery)
+		}
+	}
+
+	if err := Table("posts").WhereMatch("title", "go", "fuzzy").Err(); err == nil {
+		t.Error("invalid mode accepted")
+	}
+
+	dialect = NewPostgresDialect()
+	defer func() { dialect = nil }()
+
+	if err := Table("posts").WhereMatch("title", "go", "").Err(); err == nil {
+		t.Error("MATCH accepted on postgres")
+	}
+}
+
+func TestSelectMatchScoreOrdering(t *testing.T) {
+	query, params := Table("posts").Select("id").Where("status", 1).
+		SelectMatchScore([]string{"title"}, "+go", MatchBoolean, "score", true).
+		buildSelectSql()
+	want := "SELECT `id`, MATCH (`title`) AGAINST (? IN BOOLEAN MODE) AS `score` FROM `posts` " +
+		"WHERE `status` = ? ORDER BY `score` DESC"
+
+	if query != want {
+		t.Fatalf("sql = %q, want %q", query, want)
+	}
+
+	if len(params) != 2 || params[0] != "+go" || params[1] != 1 {
+		t.Fatalf("params = %v, want [+go 1]", params)
+	}
+
+	query, _ = Table("posts").SelectMatchScore("title", "go", "", "score").buildSelectSql()
+
+	if want := "SELECT *, MATCH (`title`) AGAINST (? IN NATURAL LANGUAGE MODE) AS `score` FROM `posts`"; query != want {
+		t.Fatalf("sql = %q, want %q", query, want)
+	}
+
+	if err := Table("posts").SelectMatchScore("title", "go", "", "score desc").Err(); err == nil {
+		t.Fatal("invalid alias accepted")
+	}
+}
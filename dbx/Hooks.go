@@ -0,0 +1,77 @@
+package dbx
+
+import (
+	"database/sql"
+)
+
+type BeforeInsertHook interface {
+	BeforeInsert(tx *sql.Tx) error
+}
+
+type AfterInsertHook interface {
+	AfterInsert(tx *sql.Tx) error
+}
+
+type BeforeUpdateHook interface {
+	BeforeUpdate(tx *sql.Tx) error
+}
+
+type AfterUpdateHook interface {
+	AfterUpdate(tx *sql.Tx) error
+}
+
+type BeforeDeleteHook interface {
+	BeforeDelete(tx *sql.Tx) error
+}
+
+type AfterFindHook interface {
+	AfterFind(tx *sql.Tx) error
+}
+
+func callBeforeInsert(tx *sql.Tx, model interface{}) error {
+	if hook, ok := model.(BeforeInsertHook); ok {
+		return hook.BeforeInsert(tx)
+	}
+
+	return nil
+}
+
+func callAfterInsert(tx *sql.Tx, model interface{}) error {
+	if hook, ok := model.(AfterInsertHook); ok {
+		return hook.AfterInsert(tx)
+	}
+
+	return nil
+}
+
+func callBeforeUpdate(tx *sql.Tx, model interface{}) error {
+	if hook, ok := model.(BeforeUpdateHook); ok {
+		return hook.BeforeUpdate(tx)
+	}
+
+	return nil
+}
+
+func callAfterUpdate(tx *sql.Tx, model interface{}) error {
+	if hook, ok := model.(AfterUpdateHook); ok {
+		return hook.AfterUpdate(tx)
+	}
+
+	return nil
+}
+
+func callBeforeDelete(tx *sql.Tx, model interface{}) error {
+	if hook, ok := model.(BeforeDeleteHook); ok {
+		return hook.BeforeDelete(tx)
+	}
+
+	return nil
+}
+
+func callAfterFind(tx *sql.Tx, model interface{}) error {
+	if hook, ok := model.(AfterFindHook); ok {
+		return hook.AfterFind(tx)
+	}
+
+	return nil
+}
@@ -0,0 +1,133 @@
+package dbx
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+type hookedPost struct {
+	Id       int64  `db:"id"`
+	Title    string `db:"title"`
+	Comments int    `db:"-"`
+}
+
+func (p *hookedPost) AfterFind(tx *sql.Tx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	return pool.QueryRowContext(ctx, "SELECT COUNT(*) FROM comments WHERE post_id = ?", p.Id).Scan(&p.Comments)
+}
+
+func TestAfterFindRunsAfterRowsClosed(t *testing.T) {
+	db := openSqliteTestDb(t,
+		"CREATE TABLE posts (id INTEGER PRIMARY KEY AUTOINCREMENT, title TEXT)",
+		"CREATE TABLE comments (id INTEGER PRIMARY KEY AUTOINCREMENT, post_id INTEGER)",
+		"INSERT INTO posts (title) VALUES ('a'), ('b')",
+		"INSERT INTO comments (post_id) VALUES (1), (1), (2)",
+	)
+
+	// a single connection makes a hook query block while the result set is still open
+	db.SetMaxOpenConns(1)
+	var list []*hookedPost
+
+	if err := Table("posts").OrderBy("id").GetInto(&list); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(list) != 2 || list[0].Comments != 2 || list[1].Comments != 1 {
+		t.Fatalf("unexpected comment counts: %+v", list)
+	}
+
+	var post hookedPost
+
+	if err := Table("posts").Where("id", 2).FirstInto(&post); err != nil {
+		t.Fatal(err)
+	}
+
+	if post.Comments != 1 {
+		t.Fatalf("post.Comments = %d, want 1", post.Comments)
+	}
+}
+
+type sluggedPost struct {
+	TrackedModel
+	Id    int64  `db:"id,pk,autoincr"`
+	Title string `db:"title"`
+	Slug  string `db:"slug"`
+}
+
+func (p *sluggedPost) BeforeUpdate(tx *sql.Tx) error {
+	p.Slug = strings.ReplaceAll(strings.ToLower(p.Title), " ", "-")
+	return nil
+}
+
+func TestSaveWritesFieldsSetByBeforeUpdate(t *testing.T) {
+	db := openSqliteTestDb(t,
+		"CREATE TABLE posts (id INTEGER PRIMARY KEY AUTOINCREMENT, title TEXT, slug TEXT)",
+		"INSERT INTO posts (title, slug) VALUES ('Old', 'old')",
+	)
+
+	var post sluggedPost
+
+	if err := Table("posts").Where("id", 1).FirstInto(&post); err != nil {
+		t.Fatal(err)
+	}
+
+	post.Title = "New Title"
+
+	if _, err := Table("posts").Save(&post); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := countRows(t, db, "SELECT COUNT(*) FROM posts WHERE slug = 'new-title'"); n != 1 {
+		t.Fatal("slug set by BeforeUpdate was not written")
+	}
+
+	if fields, _ := getDirtyFields(&post); len(fields) > 0 {
+		t.Fatalf("dirty fields after save = %v", fields)
+	}
+}
+
+type txItem struct {
+	Id   int64  `db:"id,pk,autoincr"`
+	Name string `db:"name"`
+}
+
+var txItemTxs []*sql.Tx
+
+func (i *txItem) BeforeInsert(tx *sql.Tx) error {
+	txItemTxs = append(txItemTxs, tx)
+	return nil
+}
+
+func (i *txItem) AfterInsert(tx *sql.Tx) error {
+	if i.Name == "bad" {
+		return errors.New("rejected")
+	}
+
+	return nil
+}
+
+func TestBatchInsertHooksShareTransaction(t *testing.T) {
+	db := openSqliteTestDb(t, "CREATE TABLE items (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT)")
+	txItemTxs = nil
+
+	if _, err := Table("items").BatchInsertByModels([]*txItem{{Name: "a"}, {Name: "b"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(txItemTxs) != 2 || txItemTxs[0] == nil || txItemTxs[0] != txItemTxs[1] {
+		t.Fatalf("hooks got transactions %v, want one shared transaction", txItemTxs)
+	}
+
+	if _, err := Table("items").BatchInsertByModels([]*txItem{{Name: "c"}, {Name: "bad"}}); err == nil {
+		t.Fatal("AfterInsert error not returned")
+	}
+
+	if n := countRows(t, db, "SELECT COUNT(*) FROM items"); n != 2 {
+		t.Fatalf("rows = %d, want 2 after rollback", n)
+	}
+}
@@ -0,0 +1,156 @@
+package dbx
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var regexpIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_$]*$`)
+
+var allowedOperators = map[string]bool{
+	"=": true, "<>": true, "!=": true, "<": true, "<=": true, ">": true, ">=": true, "<=>": true,
+	"LIKE": true, "NOT LIKE": true, "ILIKE": true, "NOT ILIKE": true,
+	"REGEXP": true, "NOT REGEXP": true, "IS": true, "IS NOT": true,
+}
+
+func isValidIdentifier(name string, allowStar bool) bool {
+	name = strings.NewReplacer("`", "", `"`, "").Replace(strings.TrimSpace(name))
+
+	if name == "" {
+		return false
+	}
+
+	parts := strings.Split(name, ".")
+
+	if len(parts) > 3 {
+		return false
+	}
+
+	for idx, part := range parts {
+		if allowStar && part == "*" && idx == len(parts)-1 {
+			continue
+		}
+
+		if !regexpIdentifier.MatchString(part) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isValidAlias(alias string) bool {
+	alias = strings.NewReplacer("`", "", `"`, "").Replace(strings.TrimSpace(alias))
+	return regexpIdentifier.MatchString(alias)
+}
+
+func validateNameWithAlias(kind, str string, allowStar bool) error {
+	str = strings.TrimSpace(str)
+	var parts []string
+
+	if regexpAS.MatchString(str) {
+		parts = regexpAS.Split(str, -1)
+	} else {
+		parts = regexpSpace.Split(str, -1)
+	}
+
+	switch {
+	case len(parts) == 1 && isValidIdentifier(parts[0], allowStar):
+		return nil
+	case len(parts) == 2 && isValidIdentifier(parts[0], allowStar) && isValidAlias(parts[1]):
+		return nil
+	}
+
+	return newInvalidIdentifierException(kind, str)
+}
+
+func newInvalidIdentifierException(kind, name string) error {
+	return NewDbException(fmt.Sprintf("invalid %s identifier: %q", kind, name))
+}
+
+func (qb *queryBuilder) setErr(err error) *queryBuilder {
+	if err != nil && qb.err == nil {
+		writeLog("error", err)
+		qb.err = err
+	}
+
+	return qb
+}
+
+// checkColumn records an invalid column identifier as the builder error and reports whether it was valid.
+func (qb *queryBuilder) checkColumn(columnName string) bool {
+	if isValidIdentifier(columnName, false) {
+		return true
+	}
+
+	qb.setErr(newInvalidIdentifierException("column", columnName))
+	return false
+}
+
+// checkOperator normalizes a comparison operator and records one outside allowedOperators as the builder error.
+func (qb *queryBuilder) checkOperator(operator string) (string, bool) {
+	operator = strings.ToUpper(regexpSpace.ReplaceAllString(strings.TrimSpace(operator), " "))
+
+	if allowedOperators[operator] {
+		return operator, true
+	}
+
+	qb.setErr(NewDbException(fmt.Sprintf("invalid operator: %q", operator)))
+	return "", false
+}
+
+func validateMutationData(data map[string]interface{}) error {
+	for columnName, value := range data {
+		if !isValidIdentifier(columnName, false) {
+			return newInvalidIdentifierException("column", columnName)
+		}
+
+		if v, ok := indirect(value).(rawSql); ok && v.err != nil {
+			return v.err
+		}
+	}
+
+	return nil
+}
+
+func (qb *queryBuilder) Err() error {
+	return qb.err
+}
+
+func (qb *queryBuilder) OrderBySafe(input string, allowedColumns []string) *queryBuilder {
+	for _, item := range regexpCommaSep.Split(strings.TrimSpace(input), -1) {
+		parts := regexpSpace.Split(strings.TrimSpace(item), -1)
+
+		if len(parts) < 1 || len(parts) > 2 || parts[0] == "" {
+			continue
+		}
+
+		columnName := ""
+
+		for _, allowed := range allowedColumns {
+			if strings.EqualFold(allowed, parts[0]) {
+				columnName = allowed
+				break
+			}
+		}
+
+		if columnName == "" || !isValidIdentifier(columnName, false) {
+			continue
+		}
+
+		direction := "ASC"
+
+		if len(parts) > 1 {
+			direction = strings.ToUpper(parts[1])
+		}
+
+		if direction != "ASC" && direction != "DESC" {
+			continue
+		}
+
+		qb.orderBy = append(qb.orderBy, quote(columnName)+" "+direction)
+	}
+
+	return qb
+}
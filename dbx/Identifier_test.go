@@ -0,0 +1,193 @@
+package dbx
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIsValidIdentifier(t *testing.T) {
+	cases := []struct {
+		name      string
+		allowStar bool
+		want      bool
+	}{
+		{"id", false, true},
+		{"user_id", false, true},
+		{"t.id", false, true},
+		{"db.t.id", false, true},
+		{"`t`.`id`", false, true},
+		{"t.*", true, true},
+		{"t.*", false, false},
+		{"*", true, true},
+		{"a.b.c.d", false, false},
+		{"1id", false, false},
+		{"id; DROP TABLE t", false, false},
+		{"id) OR (1=1", false, false},
+		{"COUNT(*)", true, false},
+		{"", false, false},
+	}
+
+	for _, c := range cases {
+		if got := isValidIdentifier(c.name, c.allowStar); got != c.want {
+			t.Errorf("isValidIdentifier(%q, %v) = %v, want %v", c.name, c.allowStar, got, c.want)
+		}
+	}
+}
+
+func TestValidateNameWithAlias(t *testing.T) {
+	valid := []string{"users", "users u", "users AS u", "users as u", "db.users AS u"}
+
+	for _, s1 := range valid {
+		if err := validateNameWithAlias("table", s1, false); err != nil {
+			t.Errorf("validateNameWithAlias(%q) returned %v", s1, err)
+		}
+	}
+
+	invalid := []string{"users u x", "users AS u-1", "users AS (select 1)", "users; --"}
+
+	for _, s1 := range invalid {
+		if err := validateNameWithAlias("table", s1, false); err == nil {
+			t.Errorf("validateNameWithAlias(%q) returned nil error", s1)
+		}
+	}
+}
+
+func TestParseOrderBy(t *testing.T) {
+	cases := map[string]string{
+		"id":        "`id` ASC",
+		"id desc":   "`id` DESC",
+		"t.id  ASC": "`t`.`id` ASC",
+	}
+
+	for input, want := range cases {
+		got, ok := parseOrderBy(input)
+
+		if !ok || got != want {
+			t.Errorf("parseOrderBy(%q) = %q, %v, want %q", input, got, ok, want)
+		}
+	}
+
+	for _, input := range []string{"id sideways", "id desc, name", "id; DROP TABLE t", "id desc nulls"} {
+		if _, ok := parseOrderBy(input); ok {
+			t.Errorf("parseOrderBy(%q) accepted invalid input", input)
+		}
+	}
+}
+
+func TestJoinTypes(t *testing.T) {
+	builders := map[string]*queryBuilder{
+		"INNER JOIN":       Table("a").Join("b", "b.id = a.bid"),
+		"LEFT JOIN":        Table("a").LeftJoin("b", "b.id = a.bid"),
+		"RIGHT JOIN":       Table("a").RightJoin("b", "b.id = a.bid"),
+		"CROSS JOIN":       Table("a").CrossJoin("b", "b.id = a.bid"),
+		"LEFT OUTER JOIN":  Table("a").LeftOuterJoin("b", "b.id = a.bid"),
+		"RIGHT OUTER JOIN": Table("a").RightOuterJoin("b", "b.id = a.bid"),
+	}
+
+	for want, qb := range builders {
+		if err := qb.Err(); err != nil {
+			t.Errorf("%s: unexpected error %v", want, err)
+			continue
+		}
+
+		if query, _ := qb.buildSelectSql(); !strings.Contains(query, want+" `b` ON") {
+			t.Errorf("%s: unexpected sql %q", want, query)
+		}
+	}
+
+	if err := Table("a").OuterJoin("b", "b.id = a.bid").Err(); err == nil {
+		t.Error("FULL OUTER JOIN was accepted on mysql")
+	}
+
+	dialect = NewPostgresDialect()
+	query, _ := Table("a").OuterJoin("b", "b.id = a.bid").buildSelectSql()
+	dialect = nil
+
+	if !strings.Contains(query, `FULL OUTER JOIN "b" ON`) {
+		t.Errorf("unexpected postgres outer join sql %q", query)
+	}
+
+	if err := Table("a").Join("b", "b.id = a.bid", "LEFT; DROP TABLE a").Err(); err == nil {
+		t.Error("invalid join type was accepted")
+	}
+
+	if err := Table("a").Join("b x y", "b.id = a.bid").Err(); err == nil {
+		t.Error("invalid join table was accepted")
+	}
+}
+
+func TestInvalidIdentifierFailsQuery(t *testing.T) {
+	qb := Table("a").OrderBy("id; DROP TABLE a")
+
+	if qb.Err() == nil {
+		t.Fatal("expected invalid order by to be recorded")
+	}
+
+	if _, err := qb.Count(); err != qb.Err() {
+		t.Errorf("Count() returned %v, want %v", err, qb.Err())
+	}
+
+	for _, qb := range []*queryBuilder{
+		Table("a; DROP TABLE a"),
+		Table("a").Select("id) x, y"),
+		Table("a").GroupBy("id desc"),
+		Table("a").Select("id AS x-y"),
+	} {
+		if qb.Err() == nil {
+			t.Errorf("expected identifier error for %+v", qb)
+		}
+	}
+}
+
+func TestOrderBySafe(t *testing.T) {
+	qb := Table("a").OrderBySafe("Name desc, pwd asc, id sideways, ctime, x.y z w", []string{"name", "ctime", "id"})
+	query, _ := qb.buildSelectSql()
+	want := "SELECT * FROM `a` ORDER BY `name` DESC, `ctime` ASC"
+
+	if query != want {
+		t.Errorf("OrderBySafe sql = %q, want %q", query, want)
+	}
+
+	if qb.Err() != nil {
+		t.Errorf("OrderBySafe recorded error %v", qb.Err())
+	}
+}
+
+func TestWhereRejectsInvalidColumnsAndOperators(t *testing.T) {
+	builders := map[string]*queryBuilder{
+		"where column":    Table("a").Where("id = 1 OR 1", 1),
+		"where operator":  Table("a").Where("id", "= 1 OR 1 =", 1),
+		"or where":        Table("a").OrWhere("id)", 1),
+		"where in":        Table("a").WhereIn("id; --", []int{1}),
+		"where between":   Table("a").WhereBetween("id id", 1, 2),
+		"where null":      Table("a").WhereNull("1=1 OR id"),
+		"where like":      Table("a").WhereLike("name'", "x"),
+		"where date":      Table("a").WhereDate("created_at", "<> 0 OR", "2024-01-01"),
+		"where json path": Table("a").WhereJsonPath("data", "a.b", "; DROP", 1),
+		"or where blank":  Table("a").OrWhereBlank("name,"),
+	}
+
+	for name, qb := range builders {
+		if qb.Err() == nil {
+			t.Errorf("%s: invalid input accepted", name)
+		}
+	}
+
+	qb := Table("a").Where("a.id", "not like", "x").Where("b", "<=", 2)
+
+	if err := qb.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	if query, _ := qb.buildSelectSql(); query != "SELECT * FROM `a` WHERE `a`.`id` NOT LIKE ? AND `b` <= ?" {
+		t.Errorf("unexpected sql %q", query)
+	}
+
+	if _, _, err := Table("a").buildInsertSqlByMap(map[string]interface{}{"name) VALUES (1); --": 1}); err == nil {
+		t.Error("invalid insert column accepted")
+	}
+
+	if _, _, err := Table("a").Where("id", 1).buildUpdateSqlByMap(map[string]interface{}{"x = 1, y": 1}); err == nil {
+		t.Error("invalid update column accepted")
+	}
+}
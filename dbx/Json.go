@@ -0,0 +1,171 @@
+package dbx
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+var regexpJsonPathIndex = regexp.MustCompile(`\[([0-9]+)]`)
+
+// JsonSet builds the expression that writes value at path of columnName. A value that cannot be
+// marshaled fails the insert or update the expression is used in.
+func JsonSet(columnName, path string, value interface{}) *rawSql {
+	buf, err := json.Marshal(value)
+
+	if err != nil {
+		return &rawSql{err: NewDbException(err.Error())}
+	}
+
+	expr, bindings := getDialect().JsonSetExpr(quote(columnName), normalizeJsonPath(path), string(buf))
+	return &rawSql{expr: expr, bindings: bindings}
+}
+
+func normalizeJsonPath(path string) string {
+	path = strings.TrimSpace(path)
+
+	if path == "" || path == "$" {
+		return "$"
+	}
+
+	if strings.HasPrefix(path, "$") {
+		return path
+	}
+
+	return "$." + strings.TrimPrefix(path, ".")
+}
+
+func parseJsonPath(path string) []string {
+	path = strings.TrimPrefix(normalizeJsonPath(path), "$")
+	path = regexpJsonPathIndex.ReplaceAllString(path, ".$1")
+	keys := make([]string, 0)
+
+	for _, key := range strings.Split(path, ".") {
+		key = strings.Trim(key, `"`)
+
+		if key != "" {
+			keys = append(keys, key)
+		}
+	}
+
+	return keys
+}
+
+func toPostgresJsonPath(path string) string {
+	keys := parseJsonPath(path)
+
+	for idx, key := range keys {
+		key = strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(key)
+		keys[idx] = `"` + key + `"`
+	}
+
+	return "{" + strings.Join(keys, ",") + "}"
+}
+
+func isJsonField(field reflect.StructField) bool {
+	if hasDbTagOption(field.Tag, "json") {
+		return true
+	}
+
+	s1 := strings.ToLower(field.Tag.Get("gorm"))
+	return strings.Contains(s1, "serializer:json")
+}
+
+func marshalJsonField(fv reflect.Value) (interface{}, error) {
+	switch fv.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface:
+		if fv.IsNil() {
+			return nil, nil
+		}
+	}
+
+	buf, err := json.Marshal(fv.Interface())
+
+	if err != nil {
+		return nil, NewDbException(err.Error())
+	}
+
+	return string(buf), nil
+}
+
+func unmarshalJsonField(fv reflect.Value, raw interface{}) error {
+	var buf []byte
+
+	switch v := raw.(type) {
+	case []byte:
+		buf = v
+	case string:
+		buf = []byte(v)
+	case nil:
+		fv.Set(reflect.Zero(fv.Type()))
+		return nil
+	default:
+		return NewDbException("json column must be scanned from string or []byte")
+	}
+
+	if len(buf) < 1 {
+		fv.Set(reflect.Zero(fv.Type()))
+		return nil
+	}
+
+	value := reflect.New(fv.Type())
+
+	if err := json.Unmarshal(buf, value.Interface()); err != nil {
+		return NewDbException(err.Error())
+	}
+
+	fv.Set(value.Elem())
+	return nil
+}
+
+func (qb *queryBuilder) buildJsonContainsCondition(columnName string, value interface{}) (string, []interface{}, bool) {
+	buf, err := json.Marshal(indirect(value))
+
+	if err != nil {
+		writeLog("error", err)
+		return "", nil, false
+	}
+
+	condition, params := getDialect().JsonContainsExpr(quote(columnName), string(buf))
+	return condition, params, true
+}
+
+func (qb *queryBuilder) buildJsonPathCondition(columnName, path string, args []interface{}) (string, []interface{}, bool) {
+	operator := "="
+	var bindValue interface{}
+
+	if len(args) > 1 {
+		if arg0, ok := args[0].(string); ok && arg0 != "" {
+			operator = arg0
+			bindValue = args[1]
+		}
+	} else if len(args) == 1 {
+		bindValue = args[0]
+	}
+
+	bindValue = indirect(bindValue)
+
+	if operator == "" || bindValue == nil {
+		return "", nil, false
+	}
+
+	operator, ok := qb.checkOperator(operator)
+
+	if !ok {
+		return "", nil, false
+	}
+
+	expr, params := getDialect().JsonExtractExpr(quote(columnName), normalizeJsonPath(path))
+
+	if b1, ok := bindValue.(bool); ok {
+		if b1 {
+			bindValue = "true"
+		} else {
+			bindValue = "false"
+		}
+	}
+
+	params = append(params, bindValue)
+	return strings.Join([]string{expr, operator, "?"}, " "), params, true
+}
@@ -0,0 +1,51 @@
+package dbx
+
+import (
+	"testing"
+)
+
+func TestJsonSetMarshalErrorFailsUpdate(t *testing.T) {
+	openSqliteTestDb(t,
+		"CREATE TABLE docs (id INTEGER PRIMARY KEY AUTOINCREMENT, data TEXT)",
+		`INSERT INTO docs (data) VALUES ('{"a":1}')`,
+	)
+
+	if _, err := Table("docs").Where("id", 1).JsonSet("data", "a", make(chan int)); err == nil {
+		t.Fatal("unmarshalable value accepted")
+	}
+
+	if n, err := Table("docs").Where("id", 1).JsonSet("data", "b.c", []int{2}); err != nil || n != 1 {
+		t.Fatalf("JsonSet() = %d, %v, want 1", n, err)
+	}
+
+	if n := countRows(t, pool, "SELECT COUNT(*) FROM docs WHERE json_extract(data, '$.b.c[0]') = 2 AND json_extract(data, '$.a') = 1"); n != 1 {
+		t.Fatalf("json_set left data unchanged")
+	}
+}
+
+func TestWhereJsonContainsSqlite(t *testing.T) {
+	openSqliteTestDb(t,
+		"CREATE TABLE posts (id INTEGER PRIMARY KEY AUTOINCREMENT, tags TEXT)",
+		`INSERT INTO posts (tags) VALUES ('["go","sql"]'), ('["go"]'), ('[1,2,3]'), ('[{"a":1}]')`,
+	)
+
+	cases := []struct {
+		value interface{}
+		want  int
+	}{
+		{"go", 2},
+		{[]string{"go", "sql"}, 1},
+		{[]string{"go", "rust"}, 0},
+		{2, 1},
+		{[]int{3, 1}, 1},
+		{map[string]int{"a": 1}, 1},
+	}
+
+	for _, c := range cases {
+		n, err := Table("posts").WhereJsonContains("tags", c.value).Count()
+
+		if err != nil || n != c.want {
+			t.Errorf("WhereJsonContains(%v) = %d, %v, want %d", c.value, n, err, c.want)
+		}
+	}
+}
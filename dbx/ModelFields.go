@@ -0,0 +1,347 @@
+package dbx
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+type modelField struct {
+	name      string
+	field     reflect.StructField
+	index     []int
+	prefix    string
+	column    string
+	pk        bool
+	autoIncr  bool
+	readonly  bool
+	omitEmpty bool
+	json      bool
+}
+
+type dbTag struct {
+	name    string
+	options map[string]string
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+var modelFieldsCache = &sync.Map{}
+
+func parseDbTag(tag reflect.StructTag) (dbTag, bool) {
+	s1, ok := tag.Lookup("db")
+
+	if !ok {
+		return dbTag{}, false
+	}
+
+	parts := strings.Split(s1, ",")
+	result := dbTag{name: strings.TrimSpace(parts[0]), options: map[string]string{}}
+
+	for _, part := range parts[1:] {
+		part = strings.TrimSpace(part)
+
+		if part == "" {
+			continue
+		}
+
+		if strings.Contains(part, "=") {
+			result.options[substringBefore(part, "=")] = substringAfter(part, "=")
+		} else {
+			result.options[part] = ""
+		}
+	}
+
+	return result, true
+}
+
+func getModelFields(rt reflect.Type) []modelField {
+	if rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+	}
+
+	if rt.Kind() != reflect.Struct {
+		return []modelField{}
+	}
+
+	if cached, ok := modelFieldsCache.Load(rt); ok {
+		return cached.([]modelField)
+	}
+
+	fields := make([]modelField, 0, rt.NumField())
+	collectModelFields(rt, nil, "", "", &fields)
+	fields = dedupeModelFields(fields)
+	modelFieldsCache.Store(rt, fields)
+	return fields
+}
+
+// clearModelFieldsCache drops the cached field lists, and the scan plans built on them, after a
+// change to the registered type converters decides which struct types are flattened.
+func clearModelFieldsCache() {
+	modelFieldsCache.Range(func(key, _ interface{}) bool {
+		modelFieldsCache.Delete(key)
+		return true
+	})
+
+	clearScanPlans()
+}
+
+func collectModelFields(rt reflect.Type, index []int, namePrefix, columnPrefix string, fields *[]modelField) {
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+
+		if field.Type == trackedModelType || isIgnoredField(field.Tag) || isRelationField(field.Tag) {
+			continue
+		}
+
+		fieldIndex := make([]int, len(index), len(index)+1)
+		copy(fieldIndex, index)
+		fieldIndex = append(fieldIndex, i)
+		structType := field.Type
+
+		if structType.Kind() == reflect.Ptr {
+			structType = structType.Elem()
+		}
+
+		if field.Anonymous && isFlattenableStruct(field, structType) {
+			if field.PkgPath != "" && field.Type.Kind() == reflect.Ptr {
+				continue
+			}
+
+			collectModelFields(structType, fieldIndex, namePrefix, columnPrefix, fields)
+			continue
+		}
+
+		if field.PkgPath != "" {
+			continue
+		}
+
+		if prefix, ok := getNestedPrefix(field.Tag); ok && isFlattenableStruct(field, structType) {
+			collectModelFields(structType, fieldIndex, namePrefix+field.Name+".", columnPrefix+prefix, fields)
+			continue
+		}
+
+		mf := modelField{
+			name:   namePrefix + field.Name,
+			field:  field,
+			index:  fieldIndex,
+			prefix: columnPrefix,
+			json:   isJsonField(field),
+		}
+
+		if tag, ok := parseDbTag(field.Tag); ok {
+			mf.column = tag.name
+			_, mf.pk = tag.options["pk"]
+			_, mf.autoIncr = tag.options["autoincr"]
+			_, mf.readonly = tag.options["readonly"]
+			_, mf.omitEmpty = tag.options["omitempty"]
+		}
+
+		gormTag := field.Tag.Get("gorm")
+
+		if mf.column == "" {
+			if groups := regexpGormColumn.FindStringSubmatch(gormTag); len(groups) > 1 {
+				mf.column = groups[1]
+			}
+		}
+
+		if !mf.pk {
+			mf.pk = strings.Contains(gormTag, "primary_key") || strings.Contains(gormTag, "primaryKey")
+		}
+
+		if !mf.autoIncr {
+			mf.autoIncr = strings.Contains(gormTag, "AUTO_INCREMENT") || strings.Contains(gormTag, "autoIncrement")
+		}
+
+		if !mf.readonly {
+			mf.readonly = strings.Contains(gormTag, "->;") || strings.HasSuffix(gormTag, "->")
+		}
+
+		*fields = append(*fields, mf)
+	}
+}
+
+func dedupeModelFields(fields []modelField) []modelField {
+	list := make([]modelField, 0, len(fields))
+
+	for _, item := range fields {
+		found := -1
+
+		for idx, existing := range list {
+			if existing.name == item.name {
+				found = idx
+				break
+			}
+		}
+
+		if found < 0 {
+			list = append(list, item)
+			continue
+		}
+
+		if len(item.index) < len(list[found].index) {
+			list[found] = item
+		}
+	}
+
+	return list
+}
+
+func isIgnoredField(tag reflect.StructTag) bool {
+	if s1, ok := tag.Lookup("db"); ok {
+		return s1 == "-"
+	}
+
+	return tag.Get("gorm") == "-"
+}
+
+func isRelationField(tag reflect.StructTag) bool {
+	_, ok := tag.Lookup("rel")
+	return ok
+}
+
+func isFlattenableStruct(field reflect.StructField, structType reflect.Type) bool {
+	if structType.Kind() != reflect.Struct || structType == timeType || isJsonField(field) {
+		return false
+	}
+
+	if _, ok := getTypeConverter(structType); ok {
+		return false
+	}
+
+	return !reflect.PtrTo(structType).Implements(scannerType)
+}
+
+func getNestedPrefix(tag reflect.StructTag) (string, bool) {
+	if dbTag, ok := parseDbTag(tag); ok {
+		if prefix, ok := dbTag.options["prefix"]; ok {
+			return prefix, true
+		}
+	}
+
+	gormTag := tag.Get("gorm")
+
+	if !strings.Contains(gormTag, "embedded") {
+		return "", false
+	}
+
+	for _, part := range strings.Split(gormTag, ";") {
+		part = strings.TrimSpace(part)
+
+		if strings.HasPrefix(part, "embeddedPrefix:") {
+			return strings.TrimPrefix(part, "embeddedPrefix:"), true
+		}
+	}
+
+	return "", true
+}
+
+func resolveModelField(rv reflect.Value, mf modelField, alloc bool) (reflect.Value, bool) {
+	for idx, i := range mf.index {
+		if idx > 0 && rv.Kind() == reflect.Ptr {
+			if rv.IsNil() {
+				if !alloc || !rv.CanSet() {
+					return reflect.Value{}, false
+				}
+
+				rv.Set(reflect.New(rv.Type().Elem()))
+			}
+
+			rv = rv.Elem()
+		}
+
+		rv = rv.Field(i)
+	}
+
+	return rv, true
+}
+
+func getModelFieldByName(rv reflect.Value, name string) reflect.Value {
+	for _, mf := range getModelFields(rv.Type()) {
+		if mf.name != name {
+			continue
+		}
+
+		if fv, ok := resolveModelField(rv, mf, true); ok {
+			return fv
+		}
+
+		break
+	}
+
+	return reflect.Value{}
+}
+
+func getModelFieldColumnName(tableName string, mf modelField) string {
+	if mf.column != "" {
+		return mf.prefix + mf.column
+	}
+
+	if mf.prefix == "" {
+		return getColumnNameBySturctField(tableName, mf.field.Name, mf.field.Tag)
+	}
+
+	s1 := strings.ToLower(stripColumnSeparators(mf.prefix) + mf.field.Name)
+
+	for _, item := range getTableFields(tableName) {
+		if strings.ToLower(stripColumnSeparators(item.FieldName)) == s1 {
+			return item.FieldName
+		}
+	}
+
+	return mf.prefix + lcfirst(mf.field.Name)
+}
+
+func getColumnIdxByModelField(mf modelField, columnNames []string) int {
+	if mf.column != "" {
+		s1 := strings.ToLower(mf.prefix + mf.column)
+
+		for idx, columnName := range columnNames {
+			if strings.ToLower(columnName) == s1 {
+				return idx
+			}
+		}
+
+		return -1
+	}
+
+	return getColumnIdxByStructFieldName(stripColumnSeparators(mf.prefix)+mf.field.Name, columnNames)
+}
+
+func (mf modelField) isPk(tableName, columnName string) bool {
+	return mf.pk || isPkField(tableName, columnName, mf.field.Tag)
+}
+
+func (mf modelField) isAutoIncr(tableName, columnName string) bool {
+	if mf.autoIncr {
+		return true
+	}
+
+	if field, ok := findTableField(tableName, columnName); ok {
+		return field.AutoIncrement
+	}
+
+	return false
+}
+
+func isZeroField(fv reflect.Value) bool {
+	if fv.Kind() == reflect.Ptr {
+		return fv.IsNil()
+	}
+
+	return fv.IsZero()
+}
+
+func stripColumnSeparators(str string) string {
+	return strings.NewReplacer("-", "", "_", "").Replace(str)
+}
+
+func findTableField(tableName, columnName string) (TableFieldInfo, bool) {
+	for _, item := range getTableFields(tableName) {
+		if item.FieldName == columnName {
+			return item, true
+		}
+	}
+
+	return TableFieldInfo{}, false
+}
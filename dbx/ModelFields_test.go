@@ -0,0 +1,185 @@
+package dbx
+
+import (
+	"reflect"
+	"testing"
+)
+
+type mfBase struct {
+	Id       int64  `db:"id,pk"`
+	CreateAt string `db:"create_at"`
+}
+
+type MfAudit struct {
+	Operator string `db:"operator"`
+}
+
+type mfHidden struct {
+	Secret string `db:"secret"`
+}
+
+type mfAddress struct {
+	City string `db:"city"`
+	Zip  string
+}
+
+type mfOrder struct {
+	mfBase
+	*MfAudit
+	*mfHidden
+	Id       int64     `db:"order_id,pk"`
+	Billing  mfAddress `db:",prefix=bill_"`
+	Shipping mfAddress `gorm:"embedded;embeddedPrefix:ship_"`
+	Extra    mfAddress `db:"extra,json"`
+	note     string
+	Skipped  string `db:"-"`
+}
+
+func TestModelFieldsFlattenEmbeddedStructs(t *testing.T) {
+	fields := getModelFields(reflect.TypeOf(&mfOrder{}))
+
+	want := []struct {
+		name, prefix, column string
+		depth                int
+	}{
+		{"Id", "", "order_id", 1},
+		{"CreateAt", "", "create_at", 2},
+		{"Operator", "", "operator", 2},
+		{"Billing.City", "bill_", "city", 2},
+		{"Billing.Zip", "bill_", "", 2},
+		{"Shipping.City", "ship_", "city", 2},
+		{"Shipping.Zip", "ship_", "", 2},
+		{"Extra", "", "extra", 1},
+	}
+
+	if len(fields) != len(want) {
+		t.Fatalf("got %d fields, want %d: %+v", len(fields), len(want), fields)
+	}
+
+	for idx, w := range want {
+		mf := fields[idx]
+
+		if mf.name != w.name || mf.prefix != w.prefix || mf.column != w.column || len(mf.index) != w.depth {
+			t.Errorf("field %d = {%s %s %s %v}, want %+v", idx, mf.name, mf.prefix, mf.column, mf.index, w)
+		}
+	}
+
+	if columnName := getModelFieldColumnName("orders", fields[3]); columnName != "bill_city" {
+		t.Errorf("Billing.City maps to %q, want bill_city", columnName)
+	}
+
+	var order mfOrder
+
+	if fv := getModelFieldByName(reflect.ValueOf(&order).Elem(), "Operator"); !fv.CanSet() || order.MfAudit == nil {
+		t.Fatal("embedded pointer was not allocated")
+	}
+}
+
+func TestDedupeModelFieldsKeepsShallowest(t *testing.T) {
+	fields := dedupeModelFields([]modelField{
+		{name: "Id", index: []int{0, 0}, column: "inner"},
+		{name: "Name", index: []int{0, 1}},
+		{name: "Id", index: []int{2}, column: "outer"},
+		{name: "Id", index: []int{1, 0, 0}, column: "deeper"},
+	})
+
+	if len(fields) != 2 || fields[0].column != "outer" || fields[1].name != "Name" {
+		t.Fatalf("dedupeModelFields() = %+v", fields)
+	}
+}
+
+func TestGetNestedPrefix(t *testing.T) {
+	cases := []struct {
+		tag    reflect.StructTag
+		prefix string
+		ok     bool
+	}{
+		{`db:",prefix=addr_"`, "addr_", true},
+		{`db:"addr,prefix="`, "", true},
+		{`db:"addr"`, "", false},
+		{`gorm:"embedded;embeddedPrefix:ship_"`, "ship_", true},
+		{`gorm:"embedded"`, "", true},
+		{`gorm:"column:addr"`, "", false},
+		{``, "", false},
+	}
+
+	for _, c := range cases {
+		if prefix, ok := getNestedPrefix(c.tag); prefix != c.prefix || ok != c.ok {
+			t.Errorf("getNestedPrefix(%q) = %q, %v, want %q, %v", c.tag, prefix, ok, c.prefix, c.ok)
+		}
+	}
+}
+
+func TestModelFieldTags(t *testing.T) {
+	type tagged struct {
+		Id       int64             `db:"id,pk,autoincr"`
+		Code     string            `db:" code , readonly "`
+		Memo     string            `db:"memo,omitempty"`
+		Attrs    map[string]string `db:"attrs,json"`
+		Meta     map[string]string `gorm:"serializer:json"`
+		Uid      int64             `gorm:"column:uid;primaryKey;autoIncrement"`
+		LegacyId int64             `gorm:"column: legacy_id ;primary_key;AUTO_INCREMENT"`
+		Total    int64             `gorm:"column:total;->"`
+		Rank     int64             `gorm:"->;column:rank"`
+		Name     string            `db:"name" gorm:"column:full_name;->"`
+		Plain    string
+		Ignored  string `db:"-"`
+		Gone     string `gorm:"-"`
+	}
+
+	cases := []struct {
+		name                                      string
+		column                                    string
+		pk, autoIncr, readonly, omitEmpty, isJson bool
+	}{
+		{"Id", "id", true, true, false, false, false},
+		{"Code", "code", false, false, true, false, false},
+		{"Memo", "memo", false, false, false, true, false},
+		{"Attrs", "attrs", false, false, false, false, true},
+		{"Meta", "", false, false, false, false, true},
+		{"Uid", "uid", true, true, false, false, false},
+		{"LegacyId", "legacy_id", true, true, false, false, false},
+		{"Total", "total", false, false, true, false, false},
+		{"Rank", "rank", false, false, true, false, false},
+		{"Name", "name", false, false, true, false, false},
+		{"Plain", "", false, false, false, false, false},
+	}
+
+	fields := getModelFields(reflect.TypeOf(tagged{}))
+
+	if len(fields) != len(cases) {
+		t.Fatalf("got %d fields, want %d", len(fields), len(cases))
+	}
+
+	for idx, c := range cases {
+		mf := fields[idx]
+		got := []bool{mf.pk, mf.autoIncr, mf.readonly, mf.omitEmpty, mf.json}
+		want := []bool{c.pk, c.autoIncr, c.readonly, c.omitEmpty, c.isJson}
+
+		if mf.name != c.name || mf.column != c.column || !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: got {%s %q %v}, want {%s %q %v}", c.name, mf.name, mf.column, got, c.name, c.column, want)
+		}
+	}
+}
+
+func TestParseDbTag(t *testing.T) {
+	cases := []struct {
+		tag     reflect.StructTag
+		name    string
+		options map[string]string
+		ok      bool
+	}{
+		{`db:"id"`, "id", map[string]string{}, true},
+		{`db:"id,pk,autoincr"`, "id", map[string]string{"pk": "", "autoincr": ""}, true},
+		{`db:",prefix=a_,,json"`, "", map[string]string{"prefix": "a_", "json": ""}, true},
+		{`json:"id"`, "", nil, false},
+	}
+
+	for _, c := range cases {
+		tag, ok := parseDbTag(c.tag)
+
+		if ok != c.ok || tag.name != c.name || (c.ok && !reflect.DeepEqual(tag.options, c.options)) {
+			t.Errorf("parseDbTag(%q) = %+v, %v, want %q %v, %v", c.tag, tag, ok, c.name, c.options, c.ok)
+		}
+	}
+}
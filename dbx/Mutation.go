@@ -0,0 +1,367 @@
+package dbx
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+func (qb *queryBuilder) buildJoinClause(item joinClause) (string, []interface{}) {
+	conditions := []string{item.joinOn}
+
+	if condition := qb.buildSoftDeleteScope(item.tbl, true); condition != "" {
+		conditions = append(conditions, condition)
+	}
+
+	scopeConditions, params := qb.buildGlobalScopeConditions(item.tbl, true)
+	conditions = append(conditions, scopeConditions...)
+	return strings.Join(conditions, " AND "), params
+}
+
+func (qb *queryBuilder) targetTableRef() string {
+	if qb.tables[0].alias != "" {
+		return qb.tables[0].alias
+	}
+
+	return quote(normalizeTableName(qb.tables[0].name))
+}
+
+func (qb *queryBuilder) mutationColumnName(columnName string) string {
+	if len(qb.joinClauses) < 1 {
+		return quote(columnName)
+	}
+
+	if getDialect().Name() != "mysql" {
+		if strings.Contains(columnName, ".") {
+			columnName = substringAfter(columnName, ".")
+		}
+
+		return quote(columnName)
+	}
+
+	if strings.Contains(columnName, ".") {
+		return quote(columnName)
+	}
+
+	return qb.targetTableRef() + "." + quote(columnName)
+}
+
+func (qb *queryBuilder) hasMutationLimit() bool {
+	return len(qb.limit) > 0
+}
+
+func newMutationLimitException(tableName string) DbException {
+	return NewDbException(fmt.Sprintf("limited update or delete on table [%s] needs a primary key", tableName))
+}
+
+// buildMutationOrderAndLimit builds the ORDER BY and LIMIT tail of a MySQL UPDATE or DELETE, which has
+// no OFFSET, so an offset is rejected rather than silently dropped.
+func (qb *queryBuilder) buildMutationOrderAndLimit() (string, []interface{}, error) {
+	parts := make([]string, 0, 2)
+	params := make([]interface{}, 0)
+
+	if len(qb.limit) > 1 && qb.limit[0] > 0 {
+		return "", params, NewDbException("offset is not supported in update or delete")
+	}
+
+	if len(qb.orderBy) > 0 {
+		parts = append(parts, "ORDER BY "+strings.Join(qb.orderBy, ", "))
+		params = append(params, qb.orderByBindValues...)
+	}
+
+	if len(qb.limit) > 0 {
+		parts = append(parts, getDialect().LimitClause(0, qb.limit[len(qb.limit)-1]))
+	}
+
+	return strings.Join(parts, " "), params, nil
+}
+
+func (qb *queryBuilder) buildLimitedPkCondition(conditions []string, params []interface{}) (string, []interface{}, bool) {
+	pkColumnName := getPkColumnName(qb.tables[0].name)
+
+	if pkColumnName == "" {
+		return "", params, false
+	}
+
+	qualifiedPk := qb.targetTableRef() + "." + quote(pkColumnName)
+	sb := strings.Builder{}
+	sb.WriteString("SELECT " + qualifiedPk + " FROM ")
+	sb.WriteString(qb.tables[0].nameWithAlias())
+	joins, joinParams := qb.buildJoinStatements()
+
+	if joins != "" {
+		sb.WriteString(" " + joins)
+	}
+
+	if len(conditions) > 0 {
+		sb.WriteString(" WHERE ")
+		sb.WriteString(strings.Join(conditions, " AND "))
+	}
+
+	if len(qb.orderBy) > 0 {
+		sb.WriteString(" ORDER BY ")
+		sb.WriteString(strings.Join(qb.orderBy, ", "))
+	}
+
+	if limits := qb.buildLimitStatement(); limits != "" {
+		sb.WriteString(" " + limits)
+	}
+
+	subParams := make([]interface{}, 0, len(joinParams)+len(params))
+	subParams = append(subParams, joinParams...)
+	subParams = append(subParams, params...)
+	subParams = append(subParams, qb.orderByBindValues...)
+	pk := quote(pkColumnName)
+	condition := qualifiedPk + " IN (SELECT " + pk + " FROM (" + sb.String() + ") AS " + quote("_limited") + ")"
+	return condition, subParams, true
+}
+
+func (qb *queryBuilder) buildJoinedUpdateSql(
+	updateSet []string,
+	setParams []interface{},
+) (query string, params []interface{}, err error) {
+	params = make([]interface{}, 0)
+	conditions, whereParams := qb.getScopedConditions()
+
+	if qb.hasMutationLimit() {
+		condition, subParams, ok := qb.buildLimitedPkCondition(conditions, whereParams)
+
+		if !ok {
+			err = newMutationLimitException(qb.tables[0].name)
+			return
+		}
+
+		conditions = []string{condition}
+		whereParams = subParams
+	}
+
+	sb := strings.Builder{}
+	sb.WriteString("UPDATE ")
+	sb.WriteString(qb.tables[0].nameWithAlias())
+
+	if getDialect().Name() == "mysql" {
+		joins, joinParams := qb.buildJoinStatements()
+		sb.WriteString(" " + joins)
+		sb.WriteString(" SET ")
+		sb.WriteString(strings.Join(updateSet, ", "))
+		params = append(params, joinParams...)
+		params = append(params, setParams...)
+	} else {
+		from, fromParams, onConditions, onParams := qb.buildJoinedFromClause()
+		sb.WriteString(" SET ")
+		sb.WriteString(strings.Join(updateSet, ", "))
+		sb.WriteString(" FROM " + from)
+		conditions = append(onConditions, conditions...)
+		params = append(params, setParams...)
+		params = append(params, fromParams...)
+		params = append(params, onParams...)
+	}
+
+	if len(conditions) > 0 {
+		sb.WriteString(" WHERE ")
+		sb.WriteString(strings.Join(conditions, " AND "))
+	}
+
+	params = append(params, whereParams...)
+	query = sb.String()
+	return
+}
+
+// buildJoinedDeleteSql narrows the target rows through a primary key subquery when a limit is set, and
+// always on SQLite, which has neither a multi-table DELETE nor DELETE ... USING.
+func (qb *queryBuilder) buildJoinedDeleteSql() (query string, params []interface{}, err error) {
+	params = make([]interface{}, 0)
+	conditions, whereParams := qb.getScopedConditions(false)
+	limited := false
+
+	if qb.hasMutationLimit() || getDialect().Name() == "sqlite" {
+		var condition string
+		condition, whereParams, limited = qb.buildLimitedPkCondition(conditions, whereParams)
+
+		if !limited {
+			if qb.hasMutationLimit() {
+				err = newMutationLimitException(qb.tables[0].name)
+			} else {
+				err = NewDbException(fmt.Sprintf("joined delete on sqlite table [%s] needs a primary key", qb.tables[0].name))
+			}
+
+			return
+		}
+
+		conditions = []string{condition}
+	}
+
+	sb := strings.Builder{}
+
+	switch {
+	case limited:
+		sb.WriteString("DELETE FROM ")
+		sb.WriteString(qb.tables[0].nameWithAlias())
+	case getDialect().Name() == "mysql":
+		joins, joinParams := qb.buildJoinStatements()
+		sb.WriteString("DELETE " + qb.targetTableRef() + " FROM ")
+		sb.WriteString(qb.tables[0].nameWithAlias())
+		sb.WriteString(" " + joins)
+		params = append(params, joinParams...)
+	default:
+		from, fromParams, onConditions, onParams := qb.buildJoinedFromClause()
+		sb.WriteString("DELETE FROM ")
+		sb.WriteString(qb.tables[0].nameWithAlias())
+		sb.WriteString(" USING " + from)
+		conditions = append(onConditions, conditions...)
+		params = append(params, fromParams...)
+		params = append(params, onParams...)
+	}
+
+	if len(conditions) > 0 {
+		sb.WriteString(" WHERE ")
+		sb.WriteString(strings.Join(conditions, " AND "))
+	}
+
+	params = append(params, whereParams...)
+	query = sb.String()
+	return
+}
+
+func (qb *queryBuilder) buildJoinedFromClause() (from string, fromParams []interface{}, onConditions []string, onParams []interface{}) {
+	fromParams = make([]interface{}, 0)
+	first := qb.joinClauses[0]
+	on, onParams := qb.buildJoinClause(first)
+	onConditions = []string{"(" + on + ")"}
+	sb := strings.Builder{}
+	sb.WriteString(first.tbl.nameWithAlias())
+
+	for _, item := range qb.joinClauses[1:] {
+		on, params := qb.buildJoinClause(item)
+		sb.WriteString(" " + item.joinType + " JOIN " + item.tbl.nameWithAlias() + " ON " + on)
+		fromParams = append(fromParams, params...)
+	}
+
+	from = sb.String()
+	return
+}
+
+func (qb *queryBuilder) InsertUsing(columns interface{}, subQb *queryBuilder) (int64, error) {
+	return qb.insertUsing(nil, columns, subQb)
+}
+
+func (qb *queryBuilder) TxInsertUsing(tx *sql.Tx, columns interface{}, subQb *queryBuilder) (int64, error) {
+	return qb.insertUsing(tx, columns, subQb)
+}
+
+func (qb *queryBuilder) buildInsertUsingSql(
+	columns interface{},
+	subQb *queryBuilder,
+) (query string, params []interface{}, err error) {
+	params = make([]interface{}, 0)
+
+	if len(qb.tables) < 1 || subQb == nil || subQb == qb {
+		return
+	}
+
+	subQuery, subParams := subQb.buildSelectSql()
+
+	if subQuery == "" {
+		return
+	}
+
+	var columnNames []string
+
+	if a1, ok := columns.([]string); ok {
+		columnNames = a1
+	} else if s1, ok := columns.(string); ok && s1 != "" {
+		columnNames = regexpCommaSep.Split(strings.TrimSpace(s1), -1)
+	}
+
+	scopeValues := qb.scopeValues[normalizeTableName(qb.tables[0].name)]
+	var scopeColumns []string
+	var scopeParams []interface{}
+
+	for _, columnName := range sortedMapKeys(scopeValues) {
+		value := indirect(scopeValues[columnName])
+
+		if len(columnNames) < 1 || inStringSlice(columnName, columnNames) || isListValue(value) {
+			err = NewDbException(fmt.Sprintf("column [%s] of insert using is set by global scope", columnName))
+			return
+		}
+
+		if value == nil {
+			scopeColumns = append(scopeColumns, "null")
+			continue
+		}
+
+		if v, ok := value.(rawSql); ok {
+			scopeColumns = append(scopeColumns, v.expr)
+			scopeParams = append(scopeParams, v.bindings...)
+			continue
+		}
+
+		scopeColumns = append(scopeColumns, "?")
+		scopeParams = append(scopeParams, value)
+	}
+
+	sb := strings.Builder{}
+	sb.WriteString("INSERT INTO ")
+	sb.WriteString(quote(qb.tables[0].name))
+
+	if len(columnNames) > 0 {
+		quoted := make([]string, 0, len(columnNames)+len(scopeValues))
+
+		for _, columnName := range columnNames {
+			quoted = append(quoted, quote(columnName))
+		}
+
+		for _, columnName := range sortedMapKeys(scopeValues) {
+			quoted = append(quoted, quote(columnName))
+		}
+
+		sb.WriteString(" (" + strings.Join(quoted, ", ") + ")")
+	}
+
+	if len(scopeColumns) > 0 {
+		sb.WriteString(" SELECT " + quote("dbx_src") + ".*, " + strings.Join(scopeColumns, ", "))
+		sb.WriteString(" FROM (" + subQuery + ") AS " + quote("dbx_src"))
+	} else {
+		sb.WriteString(" " + subQuery)
+	}
+
+	query = sb.String()
+	params = append(params, scopeParams...)
+	params = append(params, subParams...)
+	return
+}
+
+func (qb *queryBuilder) insertUsing(tx *sql.Tx, columns interface{}, subQb *queryBuilder) (int64, error) {
+	if subQb == nil {
+		return 0, NewDbException("param [subQb] must not be nil")
+	}
+
+	if qb.err != nil {
+		return 0, qb.err
+	}
+
+	qb = qb.Clone()
+	subQb = subQb.Clone()
+
+	if err := qb.resolveScopes(); err != nil {
+		return 0, err
+	}
+
+	if err := subQb.resolveScopes(); err != nil {
+		return 0, err
+	}
+
+	query, params, err := qb.buildInsertUsingSql(columns, subQb)
+
+	if err != nil {
+		return 0, err
+	}
+
+	query = rebind(query)
+
+	if tx != nil {
+		return TxUpdateBySql(tx, query, params, qb.getTimeout(), qb.getContext())
+	}
+
+	return UpdateBySql(query, params, qb.getTimeout(), qb.getContext())
+}
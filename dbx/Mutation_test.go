@@ -0,0 +1,48 @@
+package dbx
+
+import (
+	"testing"
+)
+
+func TestMutationOffsetRejected(t *testing.T) {
+	if _, _, err := Table("orders").Where("status", 1).Limit(10).buildDeleteSql(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := Table("orders").Where("status", 1).Limit(5, 10).buildDeleteSql(); err == nil {
+		t.Fatal("delete with offset accepted")
+	}
+
+	data := map[string]interface{}{"status": 2}
+
+	if _, _, err := Table("orders").Where("status", 1).Limit(5, 10).buildUpdateSqlByMap(data); err == nil {
+		t.Fatal("update with offset accepted")
+	}
+}
+
+func TestSqliteJoinedDelete(t *testing.T) {
+	db := openSqliteTestDb(t,
+		"CREATE TABLE orders (id INTEGER PRIMARY KEY AUTOINCREMENT, user_id INTEGER)",
+		"CREATE TABLE users (id INTEGER PRIMARY KEY AUTOINCREMENT, banned INTEGER)",
+		"CREATE TABLE order_logs (order_id INTEGER, msg TEXT)",
+		"INSERT INTO users (banned) VALUES (0), (1)",
+		"INSERT INTO orders (user_id) VALUES (1), (2), (2)",
+		"INSERT INTO order_logs (order_id, msg) VALUES (1, 'a'), (2, 'b')",
+	)
+
+	n, err := Table("orders").Join("users", "users.id = orders.user_id").Where("users.banned", 1).Delete()
+
+	if err != nil || n != 2 {
+		t.Fatalf("Delete() = %d, %v, want 2", n, err)
+	}
+
+	if n := countRows(t, db, "SELECT COUNT(*) FROM orders"); n != 1 {
+		t.Fatalf("orders left = %d, want 1", n)
+	}
+
+	_, err = Table("order_logs").Join("orders", "orders.id = order_logs.order_id").Where("orders.user_id", 1).Delete()
+
+	if err == nil {
+		t.Fatal("joined delete without primary key accepted")
+	}
+}
@@ -0,0 +1,38 @@
+package dbx
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+)
+
+func TestPluckNulls(t *testing.T) {
+	openSqliteTestDb(t,
+		"CREATE TABLE users (id INTEGER PRIMARY KEY AUTOINCREMENT, age INTEGER, name TEXT)",
+		"INSERT INTO users (age, name) VALUES (20, 'a'), (NULL, NULL), (30, 'c')",
+	)
+
+	qb := Table("users").OrderBy("id")
+	var ages []int64
+	var names []string
+
+	if err := qb.Pluck("age", &ages); err != nil || !reflect.DeepEqual(ages, []int64{20, 30}) {
+		t.Fatalf("Pluck([]int64) = %v, %v, want [20 30]", ages, err)
+	}
+
+	if err := qb.Pluck("name", &names); err != nil || !reflect.DeepEqual(names, []string{"a", "c"}) {
+		t.Fatalf("Pluck([]string) = %v, %v, want [a c]", names, err)
+	}
+
+	var agePtrs []*int64
+
+	if err := qb.Pluck("age", &agePtrs); err != nil || len(agePtrs) != 3 || agePtrs[1] != nil || *agePtrs[2] != 30 {
+		t.Fatalf("Pluck([]*int64) = %v, %v", agePtrs, err)
+	}
+
+	var nullNames []sql.NullString
+
+	if err := qb.Pluck("name", &nullNames); err != nil || len(nullNames) != 3 || nullNames[1].Valid {
+		t.Fatalf("Pluck([]sql.NullString) = %v, %v", nullNames, err)
+	}
+}
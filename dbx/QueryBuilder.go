@@ -1,10 +1,9 @@
 package dbx
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
-	"github.com/meiguonet/mgboot-go-common/util/slicex"
-	"strconv"
 	"strings"
 	"time"
 )
@@ -21,6 +20,72 @@ type queryBuilder struct {
 	includeFields []string
 	excludeFields []string
 	timeout       time.Duration
+	trashedMode   int
+	ctx           context.Context
+	withoutScopes []string
+	scopeValues   map[string]map[string]interface{}
+	withRelations []string
+	ctes          []cte
+	recursiveCte  bool
+
+	orderByBindValues []interface{}
+	groupByBindValues []interface{}
+	err               error
+}
+
+func (qb *queryBuilder) Clone() *queryBuilder {
+	c := &queryBuilder{
+		tables:        append([]table{}, qb.tables...),
+		columns:       append([]column{}, qb.columns...),
+		joinClauses:   append([]joinClause{}, qb.joinClauses...),
+		conditions:    append([]string{}, qb.conditions...),
+		bindValues:    append([]interface{}{}, qb.bindValues...),
+		orderBy:       append([]string{}, qb.orderBy...),
+		groupBy:       append([]string{}, qb.groupBy...),
+		limit:         append([]int{}, qb.limit...),
+		includeFields: append([]string{}, qb.includeFields...),
+		excludeFields: append([]string{}, qb.excludeFields...),
+		timeout:       qb.timeout,
+		trashedMode:   qb.trashedMode,
+		ctx:           qb.ctx,
+		withoutScopes: append([]string{}, qb.withoutScopes...),
+		withRelations: append([]string{}, qb.withRelations...),
+		recursiveCte:  qb.recursiveCte,
+
+		orderByBindValues: append([]interface{}{}, qb.orderByBindValues...),
+		groupByBindValues: append([]interface{}{}, qb.groupByBindValues...),
+		err:               qb.err,
+	}
+
+	if qb.scopeValues != nil {
+		c.scopeValues = make(map[string]map[string]interface{}, len(qb.scopeValues))
+
+		for tableName, values := range qb.scopeValues {
+			map1 := make(map[string]interface{}, len(values))
+
+			for columnName, value := range values {
+				map1[columnName] = value
+			}
+
+			c.scopeValues[tableName] = map1
+		}
+	}
+
+	if len(qb.ctes) > 0 {
+		c.ctes = make([]cte, 0, len(qb.ctes))
+
+		for _, item := range qb.ctes {
+			item.columns = append([]string{}, item.columns...)
+
+			if item.qb != nil {
+				item.qb = item.qb.Clone()
+			}
+
+			c.ctes = append(c.ctes, item)
+		}
+	}
+
+	return c
 }
 
 func (qb *queryBuilder) WithIncludeFields(stringOrStringSlice interface{}) *queryBuilder {
@@ -63,9 +128,55 @@ func (qb *queryBuilder) WithTimeout(timeout time.Duration) *queryBuilder {
 	return qb
 }
 
+func (qb *queryBuilder) WithContext(ctx context.Context) *queryBuilder {
+	qb.ctx = ctx
+	return qb
+}
+
+func (qb *queryBuilder) WithoutScope(names ...string) *queryBuilder {
+	qb.withoutScopes = append(qb.withoutScopes, names...)
+	return qb
+}
+
+func (qb *queryBuilder) WithTrashed() *queryBuilder {
+	qb.trashedMode = trashedModeWith
+	return qb
+}
+
+func (qb *queryBuilder) OnlyTrashed() *queryBuilder {
+	qb.trashedMode = trashedModeOnly
+	return qb
+}
+
 func (qb *queryBuilder) Select(fieldNames interface{}) *queryBuilder {
 	var columnNames []string
 
+	switch fieldNames.(type) {
+	case *windowExpr, *rawSql:
+		return qb.Select([]interface{}{fieldNames})
+	}
+
+	if a1, ok := fieldNames.([]interface{}); ok && len(a1) > 0 {
+		qb.columns = []column{}
+
+		for _, item := range a1 {
+			switch v := item.(type) {
+			case string:
+				for _, columnName := range regexpCommaSep.Split(v, -1) {
+					if columnName != "" {
+						qb.addColumn(columnName)
+					}
+				}
+			case *windowExpr:
+				qb.addWindowColumn(v)
+			case *rawSql:
+				qb.addRawColumn(v)
+			}
+		}
+
+		return qb
+	}
+
 	if a1, ok := fieldNames.([]string); ok && len(a1) > 0 {
 		columnNames = a1
 	} else if s1, ok := fieldNames.(string); ok && s1 != "" {
@@ -106,10 +217,30 @@ func (qb *queryBuilder) Join(tableName string, args ...string) *queryBuilder {
 		return qb
 	}
 
+	joinType = regexpSpace.ReplaceAllString(strings.TrimSpace(joinType), " ")
+
+	if joinType == "OUTER" {
+		joinType = "FULL OUTER"
+	}
+
 	if joinType == "" || joinOn == "" {
 		return qb
 	}
 
+	switch joinType {
+	case "INNER", "LEFT", "RIGHT", "CROSS", "FULL", "LEFT OUTER", "RIGHT OUTER", "FULL OUTER":
+	default:
+		return qb.setErr(NewDbException(fmt.Sprintf("invalid join type: %q", joinType)))
+	}
+
+	if strings.HasPrefix(joinType, "FULL") && getDialect().Name() == "mysql" {
+		return qb.setErr(NewDbException("mysql does not support FULL OUTER JOIN"))
+	}
+
+	if err := validateNameWithAlias("table", tableName, false); err != nil {
+		return qb.setErr(err)
+	}
+
 	name, alias := parseToNameAndAlias(tableName)
 
 	item := joinClause{
@@ -158,7 +289,7 @@ func (qb *queryBuilder) CrossJoin(tableName string, args ...string) *queryBuilde
 func (qb *queryBuilder) OuterJoin(tableName string, args ...string) *queryBuilder {
 	switch len(args) {
 	case 1, 3:
-		args = append(args, "OUTER")
+		args = append(args, "FULL OUTER")
 	default:
 		return qb
 	}
@@ -189,6 +320,10 @@ func (qb *queryBuilder) RightOuterJoin(tableName string, args ...string) *queryB
 }
 
 func (qb *queryBuilder) Where(columnName string, args ...interface{}) *queryBuilder {
+	if !qb.checkColumn(columnName) {
+		return qb
+	}
+
 	operator := "="
 	var bindValue interface{}
 
@@ -207,10 +342,17 @@ func (qb *queryBuilder) Where(columnName string, args ...interface{}) *queryBuil
 		return qb
 	}
 
+	operator, ok := qb.checkOperator(operator)
+
+	if !ok {
+		return qb
+	}
+
 	var condition string
 
 	if v, ok := bindValue.(rawSql); ok {
 		condition = strings.Join([]string{quote(columnName), operator, v.expr}, " ")
+		qb.addBindValues(v.bindings...)
 	} else {
 		condition = strings.Join([]string{quote(columnName), operator, "?"}, " ")
 		qb.addBindValues(bindValue)
@@ -220,6 +362,10 @@ func (qb *queryBuilder) Where(columnName string, args ...interface{}) *queryBuil
 }
 
 func (qb *queryBuilder) WhereIn(columnName string, values interface{}, not ...bool) *queryBuilder {
+	if !qb.checkColumn(columnName) {
+		return qb
+	}
+
 	var whList []string
 	var bindValues []interface{}
 
@@ -279,6 +425,10 @@ func (qb *queryBuilder) WhereNotIn(columnName string, values interface{}) *query
 }
 
 func (qb *queryBuilder) WhereBetween(columnName string, start, end interface{}, not ...bool) *queryBuilder {
+	if !qb.checkColumn(columnName) {
+		return qb
+	}
+
 	start = indirect(start)
 	end = indirect(end)
 
@@ -304,6 +454,10 @@ func (qb *queryBuilder) WhereNotBetween(columnName string, start, end interface{
 }
 
 func (qb *queryBuilder) WhereLike(columnName string, keyword string, not ...bool) *queryBuilder {
+	if !qb.checkColumn(columnName) {
+		return qb
+	}
+
 	var condition string
 
 	if len(not) > 0 && not[0] {
@@ -324,6 +478,10 @@ func (qb *queryBuilder) WhereNotLike(columnName string, keyword string) *queryBu
 }
 
 func (qb *queryBuilder) WhereRegexp(columnName string, regex string, not ...bool) *queryBuilder {
+	if !qb.checkColumn(columnName) {
+		return qb
+	}
+
 	var condition string
 
 	if len(not) > 0 && not[0] {
@@ -342,6 +500,10 @@ func (qb *queryBuilder) WhereNotRegexp(columnName string, regex string) *queryBu
 }
 
 func (qb *queryBuilder) WhereDate(columnName string, args ...string) *queryBuilder {
+	if !qb.checkColumn(columnName) {
+		return qb
+	}
+
 	operator := "="
 	var bindValue string
 
@@ -356,6 +518,12 @@ func (qb *queryBuilder) WhereDate(columnName string, args ...string) *queryBuild
 		return qb
 	}
 
+	operator, ok := qb.checkOperator(operator)
+
+	if !ok {
+		return qb
+	}
+
 	condition := fmt.Sprintf("DATE(%s) %s ?", quote(columnName), operator)
 	qb.addCondition(condition)
 	qb.addBindValues(bindValue)
@@ -400,6 +568,10 @@ func (qb *queryBuilder) WhereDateBetween(columnName, d1, d2 string, or ...bool)
 }
 
 func (qb *queryBuilder) WhereNull(columnName string, not ...bool) *queryBuilder {
+	if !qb.checkColumn(columnName) {
+		return qb
+	}
+
 	var expr string
 
 	if len(not) > 0 && not[0] {
@@ -417,6 +589,10 @@ func (qb *queryBuilder) WhereNotNull(columnName string) *queryBuilder {
 }
 
 func (qb *queryBuilder) WhereBlank(columnName string, not ...bool) *queryBuilder {
+	if !qb.checkColumn(columnName) {
+		return qb
+	}
+
 	columnName = quote(columnName)
 	sb := strings.Builder{}
 
@@ -441,60 +617,60 @@ func (qb *queryBuilder) WhereNotBlank(columnName string) *queryBuilder {
 	return qb.WhereBlank(columnName, true)
 }
 
-func (qb *queryBuilder) WhereSoftDelete(flag bool) *queryBuilder {
-	tableName := qb.tables[0].name
-
-	if strings.Contains(tableName, ".") {
-		tableName = substringAfter(tableName, ".")
+func (qb *queryBuilder) WhereJsonContains(columnName string, value interface{}) *queryBuilder {
+	if !qb.checkColumn(columnName) {
+		return qb
 	}
 
-	tableName = strings.ReplaceAll(tableName, "`", "")
-
-	schemas, ok := tableSchemas[tableName]
+	condition, params, ok := qb.buildJsonContainsCondition(columnName, value)
 
 	if !ok {
 		return qb
 	}
 
-	fieldNames := []string{
-		"del_flag",
-		"delFlag",
-	}
+	qb.addCondition(condition)
+	qb.addBindValues(params...)
+	return qb
+}
 
-	for _, item := range schemas {
-		if slicex.InStringSlice(item.FieldName, fieldNames) && strings.Contains(item.FieldType, "int") {
-			if flag {
-				return qb.Where(item.FieldName, 1)
-			} else {
-				return qb.Where(item.FieldName, 0)
-			}
-		}
+func (qb *queryBuilder) WhereJsonPath(columnName, path string, args ...interface{}) *queryBuilder {
+	if !qb.checkColumn(columnName) {
+		return qb
 	}
 
-	fieldNames = []string{
-		"delete_at",
-		"deleteAt",
-	}
+	condition, params, ok := qb.buildJsonPathCondition(columnName, path, args)
 
-	for _, item := range schemas {
-		if slicex.InStringSlice(item.FieldName, fieldNames) && item.FieldType == "datetime" {
-			if flag {
-				return qb.WhereNotNull(item.FieldName)
-			} else {
-				return qb.WhereNull(item.FieldName)
-			}
-		}
+	if !ok {
+		return qb
 	}
 
+	qb.addCondition(condition)
+	qb.addBindValues(params...)
 	return qb
 }
 
-func (qb *queryBuilder) WhereRaw(rawSql string) *queryBuilder {
+func (qb *queryBuilder) WhereSoftDelete(flag bool) *queryBuilder {
+	field, isFlag, ok := getSoftDeleteField(qb.tables[0].name)
+
+	if !ok {
+		return qb
+	}
+
+	qb.trashedMode = trashedModeWith
+	return qb.addCondition(buildSoftDeleteCondition(field.FieldName, field, isFlag, flag))
+}
+
+func (qb *queryBuilder) WhereRaw(rawSql string, bindings ...interface{}) *queryBuilder {
 	qb.addCondition(rawSql)
+	qb.addBindValues(bindings...)
 	return qb
 }
 
 func (qb *queryBuilder) OrWhere(columnName string, args ...interface{}) *queryBuilder {
+	if !qb.checkColumn(columnName) {
+		return qb
+	}
+
 	operator := "="
 	var bindValue interface{}
 
@@ -513,10 +689,17 @@ func (qb *queryBuilder) OrWhere(columnName string, args ...interface{}) *queryBu
 		return qb
 	}
 
+	operator, ok := qb.checkOperator(operator)
+
+	if !ok {
+		return qb
+	}
+
 	var condition string
 
 	if v, ok := bindValue.(rawSql); ok {
 		condition = strings.Join([]string{quote(columnName), operator, v.expr}, " ")
+		qb.addBindValues(v.bindings...)
 	} else {
 		condition = strings.Join([]string{quote(columnName), operator, "?"}, " ")
 		qb.addBindValues(bindValue)
@@ -527,6 +710,10 @@ func (qb *queryBuilder) OrWhere(columnName string, args ...interface{}) *queryBu
 }
 
 func (qb *queryBuilder) OrWhereIn(columnName string, values interface{}, not ...bool) *queryBuilder {
+	if !qb.checkColumn(columnName) {
+		return qb
+	}
+
 	var whList []string
 	var bindValues []interface{}
 
@@ -586,6 +773,10 @@ func (qb *queryBuilder) OrWhereNotIn(columnName string, values interface{}) *que
 }
 
 func (qb *queryBuilder) OrWhereBetween(columnName string, start, end interface{}, not ...bool) *queryBuilder {
+	if !qb.checkColumn(columnName) {
+		return qb
+	}
+
 	start = indirect(start)
 	end = indirect(end)
 
@@ -611,6 +802,10 @@ func (qb *queryBuilder) OrWhereNotBetween(columnName string, start, end interfac
 }
 
 func (qb *queryBuilder) OrWhereLike(columnName string, keyword string, not ...bool) *queryBuilder {
+	if !qb.checkColumn(columnName) {
+		return qb
+	}
+
 	var condition string
 
 	if len(not) > 0 && not[0] {
@@ -631,6 +826,10 @@ func (qb *queryBuilder) OrWhereNotLike(columnName string, keyword string) *query
 }
 
 func (qb *queryBuilder) OrWhereRegexp(columnName string, regex string, not ...bool) *queryBuilder {
+	if !qb.checkColumn(columnName) {
+		return qb
+	}
+
 	var condition string
 
 	if len(not) > 0 && not[0] {
@@ -649,6 +848,10 @@ func (qb *queryBuilder) OrWhereNotRegexp(columnName string, regex string) *query
 }
 
 func (qb *queryBuilder) OrWhereDate(columnName string, args ...string) *queryBuilder {
+	if !qb.checkColumn(columnName) {
+		return qb
+	}
+
 	operator := "="
 	var bindValue string
 
@@ -663,6 +866,12 @@ func (qb *queryBuilder) OrWhereDate(columnName string, args ...string) *queryBui
 		return qb
 	}
 
+	operator, ok := qb.checkOperator(operator)
+
+	if !ok {
+		return qb
+	}
+
 	condition := fmt.Sprintf("DATE(%s) %s ?", quote(columnName), operator)
 	qb.addCondition(condition, true)
 	qb.addBindValues(bindValue)
@@ -674,6 +883,10 @@ func (qb *queryBuilder) OrWhereDateBetween(columnName, d1, d2 string) *queryBuil
 }
 
 func (qb *queryBuilder) OrWhereNull(columnName string, not ...bool) *queryBuilder {
+	if !qb.checkColumn(columnName) {
+		return qb
+	}
+
 	var expr string
 
 	if len(not) > 0 && not[0] {
@@ -691,6 +904,10 @@ func (qb *queryBuilder) OrWhereNotNull(columnName string) *queryBuilder {
 }
 
 func (qb *queryBuilder) OrWhereBlank(columnName string, not ...bool) *queryBuilder {
+	if !qb.checkColumn(columnName) {
+		return qb
+	}
+
 	columnName = quote(columnName)
 	sb := strings.Builder{}
 
@@ -714,60 +931,73 @@ func (qb *queryBuilder) OrWhereNotBlank(columnName string) *queryBuilder {
 	return qb.OrWhereBlank(columnName, true)
 }
 
-func (qb *queryBuilder) OrWhereSoftDelete(flag bool) *queryBuilder {
-	tableName := qb.tables[0].name
-
-	if strings.Contains(tableName, ".") {
-		tableName = substringAfter(tableName, ".")
+func (qb *queryBuilder) OrWhereJsonContains(columnName string, value interface{}) *queryBuilder {
+	if !qb.checkColumn(columnName) {
+		return qb
 	}
 
-	tableName = strings.ReplaceAll(tableName, "`", "")
-
-	schemas, ok := tableSchemas[tableName]
+	condition, params, ok := qb.buildJsonContainsCondition(columnName, value)
 
 	if !ok {
 		return qb
 	}
 
-	fieldNames := []string{
-		"del_flag",
-		"delFlag",
-	}
+	qb.addCondition(condition, true)
+	qb.addBindValues(params...)
+	return qb
+}
 
-	for _, item := range schemas {
-		if slicex.InStringSlice(item.FieldName, fieldNames) && strings.Contains(item.FieldType, "int") {
-			if flag {
-				return qb.OrWhere(item.FieldName, 1)
-			} else {
-				return qb.OrWhere(item.FieldName, 0)
-			}
-		}
+func (qb *queryBuilder) OrWhereJsonPath(columnName, path string, args ...interface{}) *queryBuilder {
+	if !qb.checkColumn(columnName) {
+		return qb
 	}
 
-	fieldNames = []string{
-		"delete_at",
-		"deleteAt",
-	}
+	condition, params, ok := qb.buildJsonPathCondition(columnName, path, args)
 
-	for _, item := range schemas {
-		if slicex.InStringSlice(item.FieldName, fieldNames) && item.FieldType == "datetime" {
-			if flag {
-				return qb.OrWhereNotNull(item.FieldName)
-			} else {
-				return qb.OrWhereNull(item.FieldName)
-			}
-		}
+	if !ok {
+		return qb
 	}
 
+	qb.addCondition(condition, true)
+	qb.addBindValues(params...)
 	return qb
 }
 
-func (qb *queryBuilder) OrWhereRaw(rawSql string) *queryBuilder {
+func (qb *queryBuilder) OrWhereSoftDelete(flag bool) *queryBuilder {
+	field, isFlag, ok := getSoftDeleteField(qb.tables[0].name)
+
+	if !ok {
+		return qb
+	}
+
+	qb.trashedMode = trashedModeWith
+	return qb.addCondition(buildSoftDeleteCondition(field.FieldName, field, isFlag, flag), true)
+}
+
+func (qb *queryBuilder) OrWhereRaw(rawSql string, bindings ...interface{}) *queryBuilder {
 	qb.addCondition(rawSql, true)
+	qb.addBindValues(bindings...)
 	return qb
 }
 
 func (qb *queryBuilder) OrderBy(stringOrStringSlice interface{}) *queryBuilder {
+	if expr, ok := stringOrStringSlice.(*rawSql); ok {
+		return qb.addRawOrderBy(expr)
+	}
+
+	if a1, ok := stringOrStringSlice.([]interface{}); ok {
+		for _, item := range a1 {
+			switch v := item.(type) {
+			case string:
+				qb.OrderBy(v)
+			case *rawSql:
+				qb.addRawOrderBy(v)
+			}
+		}
+
+		return qb
+	}
+
 	if s1, ok := stringOrStringSlice.(string); ok {
 		if s1 == "" {
 			return qb
@@ -790,6 +1020,23 @@ func (qb *queryBuilder) OrderBy(stringOrStringSlice interface{}) *queryBuilder {
 }
 
 func (qb *queryBuilder) GroupBy(stringOrStringSlice interface{}) *queryBuilder {
+	if expr, ok := stringOrStringSlice.(*rawSql); ok {
+		return qb.addRawGroupBy(expr)
+	}
+
+	if a1, ok := stringOrStringSlice.([]interface{}); ok {
+		for _, item := range a1 {
+			switch v := item.(type) {
+			case string:
+				qb.GroupBy(v)
+			case *rawSql:
+				qb.addRawGroupBy(v)
+			}
+		}
+
+		return qb
+	}
+
 	if s1, ok := stringOrStringSlice.(string); ok {
 		if s1 == "" {
 			return qb
@@ -907,6 +1154,49 @@ func (qb *queryBuilder) TxFirstForModel(tx *sql.Tx, model interface{}) error {
 	return qb.firstForModel(tx, model)
 }
 
+func (qb *queryBuilder) GetInto(dest interface{}) error {
+	return qb.getInto(nil, dest)
+}
+
+func (qb *queryBuilder) TxGetInto(tx *sql.Tx, dest interface{}) error {
+	return qb.getInto(tx, dest)
+}
+
+func (qb *queryBuilder) FirstInto(model interface{}) error {
+	return qb.firstInto(nil, model)
+}
+
+func (qb *queryBuilder) TxFirstInto(tx *sql.Tx, model interface{}) error {
+	return qb.firstInto(tx, model)
+}
+
+// Pluck reads one column into dest, a pointer to slice. NULLs are skipped for plain element types
+// such as []int64 or []string, and kept for pointer and sql.Scanner element types such as []*int64
+// or []sql.NullString.
+func (qb *queryBuilder) Pluck(columnName string, dest interface{}) error {
+	return qb.pluck(nil, columnName, dest)
+}
+
+func (qb *queryBuilder) TxPluck(tx *sql.Tx, columnName string, dest interface{}) error {
+	return qb.pluck(tx, columnName, dest)
+}
+
+func (qb *queryBuilder) PluckMap(keyColumn, valueColumn string) (map[string]interface{}, error) {
+	return qb.pluckMap(nil, keyColumn, valueColumn)
+}
+
+func (qb *queryBuilder) TxPluckMap(tx *sql.Tx, keyColumn, valueColumn string) (map[string]interface{}, error) {
+	return qb.pluckMap(tx, keyColumn, valueColumn)
+}
+
+func (qb *queryBuilder) GetResultSet(opts ...*resultSetOptions) (*ResultSet, error) {
+	return qb.getResultSet(nil, opts...)
+}
+
+func (qb *queryBuilder) TxGetResultSet(tx *sql.Tx, opts ...*resultSetOptions) (*ResultSet, error) {
+	return qb.getResultSet(tx, opts...)
+}
+
 func (qb *queryBuilder) Value(columnName string, defaultValue ...interface{}) (interface{}, error) {
 	return qb.getColumnValue(nil, columnName, defaultValue...)
 }
@@ -979,6 +1269,14 @@ func (qb *queryBuilder) TxInsert(tx *sql.Tx, data map[string]interface{}) (int64
 	return qb.insertByMap(tx, data)
 }
 
+func (qb *queryBuilder) Upsert(data map[string]interface{}, uniqueKeys ...string) (int64, error) {
+	return qb.upsertByMap(nil, data, uniqueKeys...)
+}
+
+func (qb *queryBuilder) TxUpsert(tx *sql.Tx, data map[string]interface{}, uniqueKeys ...string) (int64, error) {
+	return qb.upsertByMap(tx, data, uniqueKeys...)
+}
+
 func (qb *queryBuilder) InsertByModel(model interface{}) (int64, error) {
 	return qb.insertByModel(nil, model)
 }
@@ -987,6 +1285,14 @@ func (qb *queryBuilder) TxInsertByModel(tx *sql.Tx, model interface{}) (int64, e
 	return qb.insertByModel(tx, model)
 }
 
+func (qb *queryBuilder) BatchInsertByModels(models interface{}) (int64, error) {
+	return qb.batchInsertByModels(nil, models)
+}
+
+func (qb *queryBuilder) TxBatchInsertByModels(tx *sql.Tx, models interface{}) (int64, error) {
+	return qb.batchInsertByModels(tx, models)
+}
+
 func (qb *queryBuilder) Update(data map[string]interface{}) (int64, error) {
 	return qb.updateByMap(nil, data)
 }
@@ -1003,6 +1309,14 @@ func (qb *queryBuilder) TxUpdateByModel(tx *sql.Tx, model interface{}) (int64, e
 	return qb.updateByModel(tx, model)
 }
 
+func (qb *queryBuilder) Save(model interface{}) (int64, error) {
+	return qb.save(nil, model)
+}
+
+func (qb *queryBuilder) TxSave(tx *sql.Tx, model interface{}) (int64, error) {
+	return qb.save(tx, model)
+}
+
 func (qb *queryBuilder) Delete() (int64, error) {
 	return qb.delete(nil)
 }
@@ -1011,112 +1325,60 @@ func (qb *queryBuilder) TxDelete(tx *sql.Tx) (int64, error) {
 	return qb.delete(tx)
 }
 
-func (qb *queryBuilder) SoftDelete() (int64, error) {
-	return qb.softDelete(nil)
+func (qb *queryBuilder) DeleteByModel(model interface{}) (int64, error) {
+	return qb.deleteByModel(nil, model)
 }
 
-func (qb *queryBuilder) TxSoftDelete(tx *sql.Tx) (int64, error) {
-	return qb.softDelete(tx)
+func (qb *queryBuilder) TxDeleteByModel(tx *sql.Tx, model interface{}) (int64, error) {
+	return qb.deleteByModel(tx, model)
 }
 
-func (qb *queryBuilder) Incr(fieldName string, num interface{}) (int64, error) {
-	expr := fieldName + " + "
+func (qb *queryBuilder) ForceDelete() (int64, error) {
+	return qb.forceDelete(nil)
+}
 
-	if n1, ok := num.(float64); ok && n1 > 0 {
-		expr += toDecimalString(n1)
-	} else if n1, ok := num.(float32); ok && n1 > 0 {
-		expr += toDecimalString(n1)
-	} else if s1, ok := num.(string); ok && s1 != "" {
-		n1, err := strconv.ParseFloat(s1, 64)
+func (qb *queryBuilder) TxForceDelete(tx *sql.Tx) (int64, error) {
+	return qb.forceDelete(tx)
+}
 
-		if err != nil || n1 < 0 {
-			return 0, nil
-		}
+func (qb *queryBuilder) Restore() (int64, error) {
+	return qb.restore(nil)
+}
 
-		expr += toDecimalString(n1)
-	} else if n1, err := strconv.Atoi(toString(num)); err == nil && n1 > 0 {
-		expr += fmt.Sprintf("%d", n1)
-	} else {
-		return 0, nil
-	}
+func (qb *queryBuilder) TxRestore(tx *sql.Tx) (int64, error) {
+	return qb.restore(tx)
+}
 
-	data := map[string]interface{}{fieldName: Raw(expr)}
-	return qb.updateByMap(nil, data)
+func (qb *queryBuilder) SoftDelete() (int64, error) {
+	return qb.softDelete(nil)
 }
 
-func (qb *queryBuilder) TxIncr(tx *sql.Tx, fieldName string, num interface{}) (int64, error) {
-	expr := fieldName + " + "
+func (qb *queryBuilder) TxSoftDelete(tx *sql.Tx) (int64, error) {
+	return qb.softDelete(tx)
+}
 
-	if n1, ok := num.(float64); ok && n1 > 0 {
-		expr += toDecimalString(n1)
-	} else if n1, ok := num.(float32); ok && n1 > 0 {
-		expr += toDecimalString(n1)
-	} else if s1, ok := num.(string); ok && s1 != "" {
-		n1, err := strconv.ParseFloat(s1, 64)
+func (qb *queryBuilder) JsonSet(columnName, path string, value interface{}) (int64, error) {
+	return qb.Update(map[string]interface{}{columnName: JsonSet(columnName, path, value)})
+}
 
-		if err != nil || n1 < 0 {
-			return 0, nil
-		}
+func (qb *queryBuilder) TxJsonSet(tx *sql.Tx, columnName, path string, value interface{}) (int64, error) {
+	return qb.TxUpdate(tx, map[string]interface{}{columnName: JsonSet(columnName, path, value)})
+}
 
-		expr += toDecimalString(n1)
-	} else if n1, err := strconv.Atoi(toString(num)); err == nil && n1 > 0 {
-		expr += fmt.Sprintf("%d", n1)
-	} else {
-		return 0, nil
-	}
+func (qb *queryBuilder) Incr(fieldName string, num interface{}) (int64, error) {
+	return qb.incr(nil, fieldName, num, "+")
+}
 
-	data := map[string]interface{}{fieldName: Raw(expr)}
-	return qb.updateByMap(tx, data)
+func (qb *queryBuilder) TxIncr(tx *sql.Tx, fieldName string, num interface{}) (int64, error) {
+	return qb.incr(tx, fieldName, num, "+")
 }
 
 func (qb *queryBuilder) Decr(fieldName string, num interface{}) (int64, error) {
-	expr := fieldName + " - "
-
-	if n1, ok := num.(float64); ok && n1 > 0 {
-		expr += toDecimalString(n1)
-	} else if n1, ok := num.(float32); ok && n1 > 0 {
-		expr += toDecimalString(n1)
-	} else if s1, ok := num.(string); ok && s1 != "" {
-		n1, err := strconv.ParseFloat(s1, 64)
-
-		if err != nil || n1 < 0 {
-			return 0, nil
-		}
-
-		expr += toDecimalString(n1)
-	} else if n1, err := strconv.Atoi(toString(num)); err == nil && n1 > 0 {
-		expr += fmt.Sprintf("%d", n1)
-	} else {
-		return 0, nil
-	}
-
-	data := map[string]interface{}{fieldName: Raw(expr)}
-	return qb.updateByMap(nil, data)
+	return qb.incr(nil, fieldName, num, "-")
 }
 
 func (qb *queryBuilder) TxDecr(tx *sql.Tx, fieldName string, num interface{}) (int64, error) {
-	expr := fieldName + " - "
-
-	if n1, ok := num.(float64); ok && n1 > 0 {
-		expr += toDecimalString(n1)
-	} else if n1, ok := num.(float32); ok && n1 > 0 {
-		expr += toDecimalString(n1)
-	} else if s1, ok := num.(string); ok && s1 != "" {
-		n1, err := strconv.ParseFloat(s1, 64)
-
-		if err != nil || n1 < 0 {
-			return 0, nil
-		}
-
-		expr += toDecimalString(n1)
-	} else if n1, err := strconv.Atoi(toString(num)); err == nil && n1 > 0 {
-		expr += fmt.Sprintf("%d", n1)
-	} else {
-		return 0, nil
-	}
-
-	data := map[string]interface{}{fieldName: Raw(expr)}
-	return qb.updateByMap(tx, data)
+	return qb.incr(tx, fieldName, num, "-")
 }
 
 func (qb *queryBuilder) SumForInt(fieldName string) (int, error) {
@@ -4,13 +4,19 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
-	"github.com/meiguonet/mgboot-go-common/util/slicex"
+	"github.com/meiguonet/mgboot-go-common/util/castx"
 	"reflect"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 )
 
 func (qb *queryBuilder) addTable(tableName string) *queryBuilder {
+	if err := validateNameWithAlias("table", tableName, false); err != nil {
+		return qb.setErr(err)
+	}
+
 	if len(qb.tables) < 1 {
 		qb.tables = make([]table, 0)
 	}
@@ -36,7 +42,20 @@ func (qb *queryBuilder) addTable(tableName string) *queryBuilder {
 	return qb
 }
 
+func (qb *queryBuilder) addRawColumn(expr *rawSql) *queryBuilder {
+	if expr == nil || expr.expr == "" {
+		return qb
+	}
+
+	qb.columns = append(qb.columns, column{expr: expr.expr, bindings: expr.bindings})
+	return qb
+}
+
 func (qb *queryBuilder) addColumn(columnName string) *queryBuilder {
+	if err := validateNameWithAlias("column", columnName, true); err != nil {
+		return qb.setErr(err)
+	}
+
 	if len(qb.columns) < 1 {
 		qb.columns = make([]column, 0)
 	}
@@ -45,7 +64,7 @@ func (qb *queryBuilder) addColumn(columnName string) *queryBuilder {
 	idx := -1
 
 	for i, item := range qb.columns {
-		if item.name == name {
+		if item.expr == "" && item.name == name {
 			idx = i
 			break
 		}
@@ -90,6 +109,26 @@ func (qb *queryBuilder) addBindValues(args ...interface{}) *queryBuilder {
 	return qb
 }
 
+func (qb *queryBuilder) addRawOrderBy(expr *rawSql) *queryBuilder {
+	if expr == nil || expr.expr == "" {
+		return qb
+	}
+
+	qb.orderBy = append(qb.orderBy, expr.expr)
+	qb.orderByBindValues = append(qb.orderByBindValues, expr.bindings...)
+	return qb
+}
+
+func (qb *queryBuilder) addRawGroupBy(expr *rawSql) *queryBuilder {
+	if expr == nil || expr.expr == "" {
+		return qb
+	}
+
+	qb.groupBy = append(qb.groupBy, expr.expr)
+	qb.groupByBindValues = append(qb.groupByBindValues, expr.bindings...)
+	return qb
+}
+
 func (qb *queryBuilder) addOrderBy(orderBy ...string) *queryBuilder {
 	if len(orderBy) < 1 {
 		return qb
@@ -100,7 +139,14 @@ func (qb *queryBuilder) addOrderBy(orderBy ...string) *queryBuilder {
 			continue
 		}
 
-		qb.orderBy = append(qb.orderBy, parseOrderBy(_orderBy))
+		s1, ok := parseOrderBy(_orderBy)
+
+		if !ok {
+			qb.setErr(newInvalidIdentifierException("order by", _orderBy))
+			continue
+		}
+
+		qb.orderBy = append(qb.orderBy, s1)
 	}
 
 	return qb
@@ -116,15 +162,22 @@ func (qb *queryBuilder) addGroupBy(groupBy ...string) *queryBuilder {
 			continue
 		}
 
+		if !isValidIdentifier(_groupBy, false) {
+			qb.setErr(newInvalidIdentifierException("group by", _groupBy))
+			continue
+		}
+
 		qb.groupBy = append(qb.groupBy, quote(_groupBy))
 	}
 
 	return qb
 }
 
-func (qb *queryBuilder) buildSelectFields() string {
+func (qb *queryBuilder) buildSelectFields() (string, []interface{}) {
+	params := make([]interface{}, 0)
+
 	if len(qb.columns) < 1 {
-		return "*"
+		return "*", params
 	}
 
 	sb := strings.Builder{}
@@ -135,14 +188,17 @@ func (qb *queryBuilder) buildSelectFields() string {
 		}
 
 		sb.WriteString(item.nameWithAlias())
+		params = append(params, item.bindings...)
 	}
 
-	return sb.String()
+	return sb.String(), params
 }
 
-func (qb *queryBuilder) buildJoinStatements() string {
+func (qb *queryBuilder) buildJoinStatements() (string, []interface{}) {
+	params := make([]interface{}, 0)
+
 	if len(qb.joinClauses) < 1 {
-		return ""
+		return "", params
 	}
 
 	sb := strings.Builder{}
@@ -156,10 +212,60 @@ func (qb *queryBuilder) buildJoinStatements() string {
 		sb.WriteString(" JOIN ")
 		sb.WriteString(item.tbl.nameWithAlias())
 		sb.WriteString(" ON ")
-		sb.WriteString(item.joinOn)
+		on, scopeParams := qb.buildJoinClause(item)
+		sb.WriteString(on)
+		params = append(params, scopeParams...)
+	}
+
+	return sb.String(), params
+}
+
+func (qb *queryBuilder) buildSoftDeleteScope(tbl table, qualified bool) string {
+	if qb.trashedMode == trashedModeWith || !SoftDeleteScopeEnabled() || qb.isCteName(tbl.name) {
+		return ""
+	}
+
+	field, isFlag, ok := getSoftDeleteField(tbl.name)
+
+	if !ok {
+		return ""
+	}
+
+	columnName := field.FieldName
+
+	if qualified {
+		if tbl.alias != "" {
+			columnName = tbl.alias + "." + columnName
+		} else {
+			columnName = normalizeTableName(tbl.name) + "." + columnName
+		}
+	}
+
+	return buildSoftDeleteCondition(columnName, field, isFlag, qb.trashedMode == trashedModeOnly)
+}
+
+func (qb *queryBuilder) getScopedConditions(softDeleteScoped ...bool) ([]string, []interface{}) {
+	conditions := make([]string, 0, len(qb.conditions)+2)
+	conditions = append(conditions, qb.conditions...)
+	params := make([]interface{}, 0, len(qb.bindValues))
+	params = append(params, qb.bindValues...)
+
+	if len(qb.tables) < 1 {
+		return conditions, params
+	}
+
+	qualified := len(qb.joinClauses) > 0
+
+	if len(softDeleteScoped) < 1 || softDeleteScoped[0] {
+		if condition := qb.buildSoftDeleteScope(qb.tables[0], qualified); condition != "" {
+			conditions = append(conditions, condition)
+		}
 	}
 
-	return sb.String()
+	scopeConditions, scopeParams := qb.buildGlobalScopeConditions(qb.tables[0], qualified)
+	conditions = append(conditions, scopeConditions...)
+	params = append(params, scopeParams...)
+	return conditions, params
 }
 
 func (qb *queryBuilder) buildLimitStatement() string {
@@ -168,10 +274,10 @@ func (qb *queryBuilder) buildLimitStatement() string {
 	}
 
 	if len(qb.limit) > 1 {
-		return fmt.Sprintf("LIMIT %d, %d", qb.limit[0], qb.limit[1])
+		return getDialect().LimitClause(qb.limit[0], qb.limit[1])
 	}
 
-	return fmt.Sprintf("LIMIT %d", qb.limit[0])
+	return getDialect().LimitClause(0, qb.limit[0])
 }
 
 func (qb *queryBuilder) buildSelectSql() (query string, params []interface{}) {
@@ -182,19 +288,28 @@ func (qb *queryBuilder) buildSelectSql() (query string, params []interface{}) {
 	}
 
 	sb := strings.Builder{}
+	cteSql, cteParams := qb.buildCteStatement()
+
+	if cteSql != "" {
+		sb.WriteString(cteSql + " ")
+	}
+
+	fields, fieldParams := qb.buildSelectFields()
 	sb.WriteString("SELECT ")
-	sb.WriteString(qb.buildSelectFields())
+	sb.WriteString(fields)
 	sb.WriteString(" FROM ")
 	sb.WriteString(qb.tables[0].nameWithAlias())
-	joins := qb.buildJoinStatements()
+	joins, joinParams := qb.buildJoinStatements()
 
 	if joins != "" {
 		sb.WriteString(" " + joins)
 	}
 
-	if len(qb.conditions) > 0 {
+	conditions, whereParams := qb.getScopedConditions()
+
+	if len(conditions) > 0 {
 		sb.WriteString(" WHERE ")
-		sb.WriteString(strings.Join(qb.conditions, " AND "))
+		sb.WriteString(strings.Join(conditions, " AND "))
 	}
 
 	if len(qb.groupBy) > 0 {
@@ -214,11 +329,12 @@ func (qb *queryBuilder) buildSelectSql() (query string, params []interface{}) {
 	}
 
 	query = sb.String()
-
-	if len(qb.bindValues) > 0 {
-		params = append(params, qb.bindValues...)
-	}
-
+	params = append(params, cteParams...)
+	params = append(params, fieldParams...)
+	params = append(params, joinParams...)
+	params = append(params, whereParams...)
+	params = append(params, qb.groupByBindValues...)
+	params = append(params, qb.orderByBindValues...)
 	return
 }
 
@@ -234,25 +350,31 @@ func (qb *queryBuilder) buildCountSql(countField string) (query string, params [
 	}
 
 	sb := strings.Builder{}
+	cteSql, cteParams := qb.buildCteStatement()
+
+	if cteSql != "" {
+		sb.WriteString(cteSql + " ")
+	}
+
 	sb.WriteString(fmt.Sprintf("SELECT COUNT(%s) FROM ", countField))
 	sb.WriteString(qb.tables[0].nameWithAlias())
-	joins := qb.buildJoinStatements()
+	joins, joinParams := qb.buildJoinStatements()
 
 	if joins != "" {
 		sb.WriteString(" " + joins)
 	}
 
-	if len(qb.conditions) > 0 {
+	conditions, whereParams := qb.getScopedConditions()
+
+	if len(conditions) > 0 {
 		sb.WriteString(" WHERE ")
-		sb.WriteString(strings.Join(qb.conditions, " AND "))
+		sb.WriteString(strings.Join(conditions, " AND "))
 	}
 
 	query = sb.String()
-
-	if len(qb.bindValues) > 0 {
-		params = append(params, qb.bindValues...)
-	}
-
+	params = append(params, cteParams...)
+	params = append(params, joinParams...)
+	params = append(params, whereParams...)
 	return
 }
 
@@ -264,43 +386,61 @@ func (qb *queryBuilder) buildSumSql(fieldName string) (query string, params []in
 	}
 
 	sb := strings.Builder{}
+	cteSql, cteParams := qb.buildCteStatement()
+
+	if cteSql != "" {
+		sb.WriteString(cteSql + " ")
+	}
+
 	sb.WriteString(fmt.Sprintf("SELECT SUM(%s) FROM ", quote(fieldName)))
 	sb.WriteString(qb.tables[0].nameWithAlias())
-	joins := qb.buildJoinStatements()
+	joins, joinParams := qb.buildJoinStatements()
 
 	if joins != "" {
 		sb.WriteString(" " + joins)
 	}
 
-	if len(qb.conditions) > 0 {
+	conditions, whereParams := qb.getScopedConditions()
+
+	if len(conditions) > 0 {
 		sb.WriteString(" WHERE ")
-		sb.WriteString(strings.Join(qb.conditions, " AND "))
+		sb.WriteString(strings.Join(conditions, " AND "))
 	}
 
 	sb.WriteString(" LIMIT 1")
 	query = sb.String()
-
-	if len(qb.bindValues) > 0 {
-		params = append(params, qb.bindValues...)
-	}
-
+	params = append(params, cteParams...)
+	params = append(params, joinParams...)
+	params = append(params, whereParams...)
 	return
 }
 
-func (qb *queryBuilder) buildInsertSqlByMap(data map[string]interface{}) (query string, params []interface{}) {
+func (qb *queryBuilder) buildInsertSqlByMap(
+	data map[string]interface{},
+	upsertKeys ...string,
+) (query string, params []interface{}, err error) {
 	params = make([]interface{}, 0)
 
 	if len(qb.tables) < 1 || len(data) < 1 {
 		return
 	}
 
+	if err = validateMutationData(data); err != nil {
+		return
+	}
+
 	autoAddCreateTime(qb.tables[0].name, data)
+
+	if err = qb.injectGlobalScopeValues(data); err != nil {
+		return
+	}
+
 	var columns []string
 	var values []string
 
-	for columnName, bindValue := range data {
+	for _, columnName := range sortedMapKeys(data) {
 		columns = append(columns, quote(columnName))
-		bindValue = indirect(bindValue)
+		bindValue := indirect(data[columnName])
 
 		if bindValue == nil {
 			values = append(values, "null")
@@ -309,6 +449,7 @@ func (qb *queryBuilder) buildInsertSqlByMap(data map[string]interface{}) (query
 
 		if v, ok := bindValue.(rawSql); ok {
 			values = append(values, v.expr)
+			params = append(params, v.bindings...)
 			continue
 		}
 
@@ -325,6 +466,25 @@ func (qb *queryBuilder) buildInsertSqlByMap(data map[string]interface{}) (query
 	sb.WriteString(" VALUES (")
 	sb.WriteString(strings.Join(values, ", "))
 	sb.WriteString(")")
+
+	if len(upsertKeys) > 0 {
+		var updateColumns []string
+
+		for _, columnName := range sortedMapKeys(data) {
+			if !inStringSlice(columnName, upsertKeys) {
+				updateColumns = append(updateColumns, columnName)
+			}
+		}
+
+		if clause := getDialect().UpsertClause(upsertKeys, updateColumns); clause != "" {
+			sb.WriteString(" " + clause)
+		}
+	}
+
+	if returning := getDialect().ReturningClause(getPkColumnName(qb.tables[0].name)); returning != "" {
+		sb.WriteString(" " + returning)
+	}
+
 	query = sb.String()
 	return
 }
@@ -332,17 +492,28 @@ func (qb *queryBuilder) buildInsertSqlByMap(data map[string]interface{}) (query
 func (qb *queryBuilder) buildInsertSqlByModel(
 	rt reflect.Type,
 	rv reflect.Value,
-) (query, pkField string, params []interface{}) {
-	defer func() {
-		qb.includeFields = []string{}
-		qb.excludeFields = []string{}
-	}()
+) (query, pkField string, params []interface{}, err error) {
+	var data map[string]interface{}
+	data, pkField, err = qb.buildInsertDataByModel(rt, rv)
+
+	if err != nil {
+		return
+	}
+
+	query, params, err = qb.buildInsertSqlByMap(data)
+	return
+}
 
+func (qb *queryBuilder) buildInsertDataByModel(
+	rt reflect.Type,
+	rv reflect.Value,
+) (data map[string]interface{}, pkField string, err error) {
 	tableName := qb.tables[0].name
-	data := map[string]interface{}{}
+	data = map[string]interface{}{}
+	var pkFound bool
 
-	for i := 0; i < rt.NumField(); i++ {
-		fieldName := rt.Field(i).Name
+	for _, mf := range getModelFields(rt) {
+		fieldName := mf.name
 
 		if len(qb.includeFields) > 0 && !inStringSlice(fieldName, qb.includeFields) {
 			continue
@@ -352,146 +523,261 @@ func (qb *queryBuilder) buildInsertSqlByModel(
 			continue
 		}
 
-		tag := rt.Field(i).Tag
-		columnName := getColumnNameBySturctField(tableName, fieldName, tag)
+		columnName := getModelFieldColumnName(tableName, mf)
 
 		if columnName == "" {
 			continue
 		}
 
-		if pkField == "" && isPkField(tableName, columnName, tag) {
-			pkField = fieldName
+		field, ok := resolveModelField(rv, mf, false)
+
+		if !ok {
 			continue
 		}
 
-		field := rv.Field(i)
-
-		if t1, ok := field.Interface().(time.Time); ok {
-			s1 := qb.handleDatetimeFieldInModel(tableName, columnName, &t1)
+		if !pkFound && mf.isPk(tableName, columnName) {
+			pkFound = true
 
-			if inStringSlice(s1, []string{"NotExists", "NotMatched", "NotNullable"}) {
+			if mf.isAutoIncr(tableName, columnName) || isZeroField(field) || reflect.Indirect(field).IsZero() {
+				pkField = fieldName
 				continue
 			}
+		}
 
-			if s1 == "nil" {
-				data[columnName] = nil
-			} else {
-				data[columnName] = s1
-			}
-
+		if mf.readonly || (mf.omitEmpty && isZeroField(field)) {
 			continue
 		}
 
-		if t1, ok := field.Interface().(*time.Time); ok {
-			s1 := qb.handleDatetimeFieldInModel(tableName, columnName, t1)
+		if mf.json {
+			value, err := marshalJsonField(field)
 
-			if inStringSlice(s1, []string{"NotExists", "NotMatched", "NotNullable"}) {
-				continue
+			if err != nil {
+				return nil, pkField, err
 			}
 
-			if s1 == "nil" {
-				data[columnName] = nil
-			} else {
-				data[columnName] = s1
+			data[columnName] = value
+			continue
+		}
+
+		if t1, ok := field.Interface().(time.Time); ok {
+			if value, ok := qb.handleDatetimeFieldInModel(tableName, columnName, &t1); ok {
+				data[columnName] = value
 			}
 
 			continue
 		}
 
-		if field.Kind() == reflect.Ptr {
-			if field.IsNil() {
-				data[columnName] = nil
-			} else {
-				data[columnName] = field.Elem().Interface()
+		if t1, ok := field.Interface().(*time.Time); ok {
+			if value, ok := qb.handleDatetimeFieldInModel(tableName, columnName, t1); ok {
+				data[columnName] = value
 			}
 
 			continue
 		}
 
-		data[columnName] = field.Interface()
+		value, err := getModelFieldValue(field)
+
+		if err != nil {
+			return nil, pkField, err
+		}
+
+		data[columnName] = value
+	}
+
+	return
+}
+
+func (qb *queryBuilder) buildBatchInsertSql(rows []map[string]interface{}) (query string, params []interface{}, err error) {
+	params = make([]interface{}, 0)
+
+	if len(qb.tables) < 1 || len(rows) < 1 {
+		return
+	}
+
+	columnNames := make([]string, 0)
+
+	for _, data := range rows {
+		if err = validateMutationData(data); err != nil {
+			return
+		}
+
+		autoAddCreateTime(qb.tables[0].name, data)
+
+		if err = qb.injectGlobalScopeValues(data); err != nil {
+			return
+		}
+
+		for columnName := range data {
+			if !inStringSlice(columnName, columnNames) {
+				columnNames = append(columnNames, columnName)
+			}
+		}
+	}
+
+	sort.Strings(columnNames)
+	columns := make([]string, 0, len(columnNames))
+
+	for _, columnName := range columnNames {
+		columns = append(columns, quote(columnName))
+	}
+
+	sb := strings.Builder{}
+	sb.WriteString("INSERT INTO ")
+	sb.WriteString(qb.tables[0].nameWithAlias())
+	sb.WriteString(" (")
+	sb.WriteString(strings.Join(columns, ", "))
+	sb.WriteString(") VALUES ")
+
+	for idx, data := range rows {
+		if idx > 0 {
+			sb.WriteString(", ")
+		}
+
+		values := make([]string, 0, len(columnNames))
+
+		for _, columnName := range columnNames {
+			value, ok := data[columnName]
+
+			// a column only other rows set takes its table default, not null, which NOT NULL columns reject;
+			// SQLite has no DEFAULT in VALUES, and batch inserts there go row by row
+			if !ok && getDialect().Name() != "sqlite" {
+				values = append(values, "DEFAULT")
+				continue
+			}
+
+			bindValue := indirect(value)
+
+			if bindValue == nil {
+				values = append(values, "null")
+				continue
+			}
+
+			if v, ok := bindValue.(rawSql); ok {
+				values = append(values, v.expr)
+				params = append(params, v.bindings...)
+				continue
+			}
+
+			values = append(values, "?")
+			params = append(params, bindValue)
+		}
+
+		sb.WriteString("(")
+		sb.WriteString(strings.Join(values, ", "))
+		sb.WriteString(")")
+	}
+
+	if returning := getDialect().ReturningClause(getPkColumnName(qb.tables[0].name)); returning != "" {
+		sb.WriteString(" " + returning)
 	}
 
-	query, params = qb.buildInsertSqlByMap(data)
+	query = sb.String()
 	return
 }
 
-func (qb *queryBuilder) buildUpdateSqlByMap(data map[string]interface{}) (query string, params []interface{}) {
+func (qb *queryBuilder) buildUpdateSqlByMap(data map[string]interface{}) (query string, params []interface{}, err error) {
 	params = make([]interface{}, 0)
 
 	if len(qb.tables) < 1 || len(data) < 1 {
 		return
 	}
 
+	if err = validateMutationData(data); err != nil {
+		return
+	}
+
 	autoAddUpdateTime(qb.tables[0].name, data)
 	var updateSet []string
 
-	for columnName, bindValue := range data {
-		bindValue = indirect(bindValue)
+	for _, key := range sortedMapKeys(data) {
+		columnName := qb.mutationColumnName(key)
+		bindValue := indirect(data[key])
 
 		if bindValue == nil {
-			updateSet = append(updateSet, quote(columnName) + " = null")
+			updateSet = append(updateSet, columnName+" = null")
 			continue
 		}
 
 		if v, ok := bindValue.(rawSql); ok {
-			updateSet = append(updateSet, quote(columnName) + " = " + v.expr)
+			updateSet = append(updateSet, columnName+" = "+v.expr)
+			params = append(params, v.bindings...)
 			continue
 		}
 
-		updateSet = append(updateSet, quote(columnName) + " = ?")
+		updateSet = append(updateSet, columnName+" = ?")
 		params = append(params, bindValue)
 	}
 
+	if len(qb.joinClauses) > 0 {
+		return qb.buildJoinedUpdateSql(updateSet, params)
+	}
+
+	conditions, whereParams := qb.getScopedConditions()
+	tail, tailParams := "", make([]interface{}, 0)
+
+	if getDialect().Name() == "mysql" {
+		if tail, tailParams, err = qb.buildMutationOrderAndLimit(); err != nil {
+			return
+		}
+	} else if qb.hasMutationLimit() {
+		condition, subParams, ok := qb.buildLimitedPkCondition(conditions, whereParams)
+
+		if !ok {
+			err = newMutationLimitException(qb.tables[0].name)
+			return
+		}
+
+		conditions = []string{condition}
+		whereParams = subParams
+	}
+
 	sb := strings.Builder{}
 	sb.WriteString("UPDATE ")
 	sb.WriteString(qb.tables[0].nameWithAlias())
 	sb.WriteString(" SET ")
 	sb.WriteString(strings.Join(updateSet, ", "))
 
-	if len(qb.conditions) > 0 {
+	if len(conditions) > 0 {
 		sb.WriteString(" WHERE ")
-		sb.WriteString(strings.Join(qb.conditions, " AND "))
+		sb.WriteString(strings.Join(conditions, " AND "))
 	}
 
-	if len(qb.bindValues) > 0 {
-		params = append(params, qb.bindValues...)
+	if tail != "" {
+		sb.WriteString(" " + tail)
 	}
 
+	params = append(params, whereParams...)
+	params = append(params, tailParams...)
+
 	query = sb.String()
 	return
 }
 
-func (qb *queryBuilder) buildUpdateSqlByModel(rt reflect.Type, rv reflect.Value) (query string, params []interface{}) {
-	defer func() {
-		qb.includeFields = []string{}
-		qb.excludeFields = []string{}
-	}()
-
+func (qb *queryBuilder) buildUpdateSqlByModel(
+	rt reflect.Type,
+	rv reflect.Value,
+) (query, versionField string, params []interface{}, err error) {
 	tableName := qb.tables[0].name
 	var pkField string
+	var versionColumn string
+	var versionValue int64
 	data := map[string]interface{}{}
 
-	for i := 0; i < rt.NumField(); i++ {
-		fieldName := rt.Field(i).Name
+	for _, mf := range getModelFields(rt) {
+		fieldName := mf.name
+		columnName := getModelFieldColumnName(tableName, mf)
 
-		if len(qb.includeFields) > 0 && !inStringSlice(fieldName, qb.includeFields) {
-			continue
-		}
-
-		if len(qb.excludeFields) > 0 && inStringSlice(fieldName, qb.excludeFields) {
+		if columnName == "" {
 			continue
 		}
 
-		tag := rt.Field(i).Tag
-		columnName := getColumnNameBySturctField(tableName, fieldName, tag)
+		field, ok := resolveModelField(rv, mf, false)
 
-		if columnName == "" {
+		if !ok {
 			continue
 		}
 
-		field := rv.Field(i)
-
-		if pkField == "" && isPkField(tableName, columnName, tag) {
+		if pkField == "" && mf.isPk(tableName, columnName) {
 			var pkValue interface{}
 
 			if field.Kind() == reflect.Ptr {
@@ -507,102 +793,143 @@ func (qb *queryBuilder) buildUpdateSqlByModel(rt reflect.Type, rv reflect.Value)
 			continue
 		}
 
-		if t1, ok := field.Interface().(time.Time); ok {
-			s1 := qb.handleDatetimeFieldInModel(tableName, columnName, &t1)
+		if versionField == "" && isVersionField(tableName, columnName, mf.field) {
+			versionField = fieldName
+			versionColumn = columnName
+			versionValue = reflect.Indirect(field).Convert(reflect.TypeOf(int64(0))).Int()
+			data[columnName] = Raw(quote(columnName) + " + 1")
+			continue
+		}
 
-			if inStringSlice(s1, []string{"NotExists", "NotMatched", "NotNullable"}) {
-				continue
-			}
+		if len(qb.includeFields) > 0 && !inStringSlice(fieldName, qb.includeFields) {
+			continue
+		}
 
-			if s1 == "nil" {
-				data[columnName] = nil
-			} else {
-				data[columnName] = s1
-			}
+		if len(qb.excludeFields) > 0 && inStringSlice(fieldName, qb.excludeFields) {
+			continue
+		}
 
+		if mf.readonly || (mf.omitEmpty && isZeroField(field)) {
 			continue
 		}
 
-		if t1, ok := field.Interface().(*time.Time); ok {
-			s1 := qb.handleDatetimeFieldInModel(tableName, columnName, t1)
+		if mf.json {
+			value, err := marshalJsonField(field)
 
-			if inStringSlice(s1, []string{"NotExists", "NotMatched", "NotNullable"}) {
-				continue
+			if err != nil {
+				return "", "", nil, err
 			}
 
-			if s1 == "nil" {
-				data[columnName] = nil
-			} else {
-				data[columnName] = s1
+			data[columnName] = value
+			continue
+		}
+
+		if t1, ok := field.Interface().(time.Time); ok {
+			if value, ok := qb.handleDatetimeFieldInModel(tableName, columnName, &t1); ok {
+				data[columnName] = value
 			}
 
 			continue
 		}
 
-		if field.Kind() == reflect.Ptr {
-			if field.IsNil() {
-				data[columnName] = nil
-			} else {
-				data[columnName] = field.Elem().Interface()
+		if t1, ok := field.Interface().(*time.Time); ok {
+			if value, ok := qb.handleDatetimeFieldInModel(tableName, columnName, t1); ok {
+				data[columnName] = value
 			}
 
 			continue
 		}
 
-		data[columnName] = field.Interface()
+		value, err := getModelFieldValue(field)
+
+		if err != nil {
+			return "", "", nil, err
+		}
+
+		data[columnName] = value
+	}
+
+	if versionColumn != "" {
+		qb.Where(versionColumn, versionValue)
 	}
 
-	query, params = qb.buildUpdateSqlByMap(data)
+	query, params, err = qb.buildUpdateSqlByMap(data)
 	return
 }
 
-func (qb *queryBuilder) buildDeleteSql() (query string, params []interface{}) {
+func (qb *queryBuilder) buildDeleteSql() (query string, params []interface{}, err error) {
 	params = make([]interface{}, 0)
 
 	if len(qb.tables) < 1 {
 		return
 	}
 
+	if len(qb.joinClauses) > 0 {
+		return qb.buildJoinedDeleteSql()
+	}
+
+	conditions, whereParams := qb.getScopedConditions(false)
+	tail, tailParams := "", make([]interface{}, 0)
+
+	if getDialect().Name() == "mysql" {
+		if tail, tailParams, err = qb.buildMutationOrderAndLimit(); err != nil {
+			return
+		}
+	} else if qb.hasMutationLimit() {
+		condition, subParams, ok := qb.buildLimitedPkCondition(conditions, whereParams)
+
+		if !ok {
+			err = newMutationLimitException(qb.tables[0].name)
+			return
+		}
+
+		conditions = []string{condition}
+		whereParams = subParams
+	}
+
 	sb := strings.Builder{}
 	sb.WriteString("DELETE FROM ")
 	sb.WriteString(qb.tables[0].nameWithAlias())
 
-	if len(qb.conditions) > 0 {
+	if len(conditions) > 0 {
 		sb.WriteString(" WHERE ")
-		sb.WriteString(strings.Join(qb.conditions, " AND "))
+		sb.WriteString(strings.Join(conditions, " AND "))
 	}
 
-	query = sb.String()
-
-	if len(qb.bindValues) > 0 {
-		params = append(params, qb.bindValues...)
+	if tail != "" {
+		sb.WriteString(" " + tail)
 	}
 
+	query = sb.String()
+	params = append(params, whereParams...)
+	params = append(params, tailParams...)
+
 	return
 }
 
 func (qb *queryBuilder) getForMapList(tx *sql.Tx, fieldNames ...interface{}) ([]map[string]interface{}, error) {
-	defer func() {
-		qb.timeout = 0
-	}()
+	qb = qb.Clone()
 
 	if len(fieldNames) > 0 {
 		qb.Select(fieldNames[0])
 	}
 
+	if err := qb.resolveScopes(); err != nil {
+		return []map[string]interface{}{}, err
+	}
+
 	query, params := qb.buildSelectSql()
+	query = rebind(query)
 
 	if tx != nil {
-		return TxSelectBySql(tx, query, params, qb.getTimeout())
+		return TxSelectBySql(tx, query, params, qb.getTimeout(), qb.getContext())
 	}
 
-	return SelectBySql(query, params, qb.getTimeout())
+	return SelectBySql(query, params, qb.getTimeout(), qb.getContext())
 }
 
 func (qb *queryBuilder) getForModels(tx *sql.Tx, model interface{}, eachFn func(interface{})) error {
-	defer func() {
-		qb.timeout = 0
-	}()
+	qb = qb.Clone()
 
 	err1 := NewDbException("model is not struct pointer")
 
@@ -616,21 +943,149 @@ func (qb *queryBuilder) getForModels(tx *sql.Tx, model interface{}, eachFn func(
 		return err1
 	}
 
-	rt = rt.Elem()
+	if len(qb.withRelations) < 1 {
+		return qb.queryForModels(tx, rt.Elem(), func(rv reflect.Value) {
+			eachFn(rv.Elem().Interface())
+		})
+	}
 
-	query, params := qb.buildSelectSql()
-	logSql(query, params)
-	var err error
+	parents := make([]reflect.Value, 0)
 
-	if pool == nil {
-		err = NewDbException("database connection pool is nil")
-		writeLog("error", err)
+	err := qb.queryForModels(tx, rt.Elem(), func(rv reflect.Value) {
+		parents = append(parents, rv.Elem())
+	})
+
+	if err != nil {
 		return err
 	}
 
-	ctx, cancel := context.WithTimeout(context.TODO(), qb.getTimeout())
-	defer cancel()
-	var rs *sql.Rows
+	if err := qb.loadRelations(tx, qb.tables[0].name, parents, qb.withRelations); err != nil {
+		return err
+	}
+
+	for _, item := range parents {
+		eachFn(item.Interface())
+	}
+
+	return nil
+}
+
+func (qb *queryBuilder) getInto(tx *sql.Tx, dest interface{}) error {
+	qb = qb.Clone()
+
+	err1 := NewDbException("param [dest] must be a pointer to slice of struct or struct pointer")
+	rv := reflect.ValueOf(dest)
+
+	if dest == nil || rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Slice {
+		return err1
+	}
+
+	sliceValue := rv.Elem()
+	elemType := sliceValue.Type().Elem()
+	isPtr := elemType.Kind() == reflect.Ptr
+	structType := elemType
+
+	if isPtr {
+		structType = elemType.Elem()
+	}
+
+	if structType.Kind() != reflect.Struct {
+		return err1
+	}
+
+	list := reflect.MakeSlice(sliceValue.Type(), 0, 0)
+
+	err := qb.queryForModels(tx, structType, func(item reflect.Value) {
+		if isPtr {
+			list = reflect.Append(list, item)
+		} else {
+			list = reflect.Append(list, item.Elem())
+		}
+	})
+
+	if err != nil {
+		return err
+	}
+
+	if len(qb.withRelations) > 0 {
+		parents := make([]reflect.Value, 0, list.Len())
+
+		for i := 0; i < list.Len(); i++ {
+			parents = append(parents, reflect.Indirect(list.Index(i)))
+		}
+
+		if err := qb.loadRelations(tx, qb.tables[0].name, parents, qb.withRelations); err != nil {
+			return err
+		}
+	}
+
+	sliceValue.Set(list)
+	return nil
+}
+
+// queryForModels runs AfterFind hooks only after the rows are closed, so that a hook may issue
+// queries on the same connection or transaction.
+func (qb *queryBuilder) queryForModels(tx *sql.Tx, rt reflect.Type, eachFn func(rv reflect.Value)) error {
+	var scanner *modelScanner
+	items := make([]reflect.Value, 0)
+
+	err := qb.queryForRows(tx, func(rs *sql.Rows) (bool, error) {
+		rv := reflect.New(rt)
+
+		if scanner == nil {
+			var err error
+
+			if scanner, err = newModelScanner(rs, rv.Type()); err != nil {
+				return false, err
+			}
+		}
+
+		if err := scanner.scan(rs, rv.Interface()); err != nil {
+			return false, err
+		}
+
+		rememberOriginalValues(rv.Interface())
+		items = append(items, rv)
+		return true, nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	for _, rv := range items {
+		if err := callAfterFind(tx, rv.Interface()); err != nil {
+			return err
+		}
+
+		eachFn(rv)
+	}
+
+	return nil
+}
+
+func (qb *queryBuilder) queryForRows(
+	tx *sql.Tx, eachFn func(rs *sql.Rows) (bool, error),
+	prepareFn ...func(rs *sql.Rows) error,
+) error {
+	if err := qb.resolveScopes(); err != nil {
+		return err
+	}
+
+	query, params := qb.buildSelectSql()
+	query = rebind(query)
+	logSql(query, params)
+	var err error
+
+	if pool == nil {
+		err = NewDbException("database connection pool is nil")
+		writeLog("error", err)
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(qb.getContext(), qb.getTimeout())
+	defer cancel()
+	var rs *sql.Rows
 
 	if tx != nil {
 		rs, err = tx.QueryContext(ctx, query, params...)
@@ -650,14 +1105,23 @@ func (qb *queryBuilder) getForModels(tx *sql.Tx, model interface{}, eachFn func(
 
 	defer rs.Close()
 
+	for _, fn := range prepareFn {
+		if err = fn(rs); err != nil {
+			writeLog("error", err)
+			return err
+		}
+	}
+
 	for rs.Next() {
-		rv := reflect.New(rt)
+		var next bool
 
-		if err = scanIntoModel(rs, rv.Interface()); err != nil {
+		if next, err = eachFn(rs); err != nil || !next {
 			break
 		}
+	}
 
-		eachFn(rv.Elem().Interface())
+	if err == nil {
+		err = rs.Err()
 	}
 
 	if err != nil {
@@ -668,9 +1132,7 @@ func (qb *queryBuilder) getForModels(tx *sql.Tx, model interface{}, eachFn func(
 }
 
 func (qb *queryBuilder) firstForMap(tx *sql.Tx, fieldNames ...interface{}) (map[string]interface{}, error) {
-	defer func() {
-		qb.timeout = 0
-	}()
+	qb = qb.Clone()
 
 	if len(fieldNames) > 0 {
 		qb.Select(fieldNames[0])
@@ -691,53 +1153,156 @@ func (qb *queryBuilder) firstForMap(tx *sql.Tx, fieldNames ...interface{}) (map[
 }
 
 func (qb *queryBuilder) firstForModel(tx *sql.Tx, model interface{}) error {
-	defer func() {
-		qb.timeout = 0
-	}()
+	_, err := qb.firstForModelFound(tx, model)
+	return err
+}
 
-	qb.limit = []int{1}
-	query, params := qb.buildSelectSql()
-	logSql(query, params)
-	var err error
+func (qb *queryBuilder) firstInto(tx *sql.Tx, model interface{}) error {
+	found, err := qb.firstForModelFound(tx, model)
 
-	if pool == nil {
-		err = NewDbException("database connection pool is nil")
-		writeLog("error", err)
+	if err != nil {
 		return err
 	}
 
-	ctx, cancel := context.WithTimeout(context.TODO(), qb.getTimeout())
-	defer cancel()
-	var rs *sql.Rows
+	if !found {
+		return NewNoDataException()
+	}
 
-	if tx != nil {
-		rs, err = tx.QueryContext(ctx, query, params...)
-	} else {
-		rs, err = pool.QueryContext(ctx, query, params...)
+	return nil
+}
+
+func (qb *queryBuilder) firstForModelFound(tx *sql.Tx, model interface{}) (bool, error) {
+	qb = qb.Clone()
+
+	if rt := reflect.TypeOf(model); rt == nil || rt.Kind() != reflect.Ptr || rt.Elem().Kind() != reflect.Struct {
+		return false, NewDbException("model is not struct pointer")
+	}
+
+	qb.limit = []int{1}
+	var found bool
+
+	err := qb.queryForRows(tx, func(rs *sql.Rows) (bool, error) {
+		if err := scanIntoModel(rs, model); err != nil {
+			return false, err
+		}
+
+		found = true
+		rememberOriginalValues(model)
+		return false, nil
+	})
+
+	if err != nil || !found {
+		return found, err
+	}
+
+	if err := callAfterFind(tx, model); err != nil {
+		return found, err
+	}
+
+	if len(qb.withRelations) < 1 {
+		return found, nil
+	}
+
+	parents := []reflect.Value{reflect.ValueOf(model).Elem()}
+	return found, qb.loadRelations(tx, qb.tables[0].name, parents, qb.withRelations)
+}
+
+func (qb *queryBuilder) pluck(tx *sql.Tx, columnName string, dest interface{}) error {
+	qb = qb.Clone()
+
+	rv := reflect.ValueOf(dest)
+
+	if dest == nil || rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Slice {
+		return NewDbException("param [dest] must be a pointer to slice")
 	}
 
+	qb.Select(columnName)
+	sliceValue := rv.Elem()
+	elemType := sliceValue.Type().Elem()
+	list := reflect.MakeSlice(sliceValue.Type(), 0, 0)
+
+	nullable := elemType.Kind() == reflect.Ptr || reflect.PtrTo(elemType).Implements(scannerType)
+
+	err := qb.queryForRows(tx, func(rs *sql.Rows) (bool, error) {
+		if nullable {
+			item := reflect.New(elemType)
+
+			if err := rs.Scan(item.Interface()); err != nil {
+				return false, err
+			}
+
+			list = reflect.Append(list, item.Elem())
+			return true, nil
+		}
+
+		item := reflect.New(reflect.PtrTo(elemType))
+
+		if err := rs.Scan(item.Interface()); err != nil {
+			return false, err
+		}
+
+		if !item.Elem().IsNil() {
+			list = reflect.Append(list, item.Elem().Elem())
+		}
+
+		return true, nil
+	})
+
 	if err != nil {
-		writeLog("error", err)
-		return toDbException(err)
+		return err
 	}
 
-	if ctx.Err() != nil {
-		writeLog("error", ctx.Err())
-		return toDbException(ctx.Err())
+	sliceValue.Set(list)
+	return nil
+}
+
+func (qb *queryBuilder) pluckMap(tx *sql.Tx, keyColumn, valueColumn string) (map[string]interface{}, error) {
+	map1 := map[string]interface{}{}
+	list, err := qb.getForMapList(tx, []string{keyColumn, valueColumn})
+
+	if err != nil {
+		return map1, err
 	}
 
-	defer rs.Close()
+	keyName := getResultColumnName(keyColumn)
+	valueName := getResultColumnName(valueColumn)
 
-	for rs.Next() {
-		err = scanIntoModel(rs, model)
-		break
+	for _, item := range list {
+		key := castx.ToString(item[keyName])
+
+		if key == "" {
+			continue
+		}
+
+		map1[key] = item[valueName]
 	}
 
+	return map1, nil
+}
+
+func (qb *queryBuilder) getResultSet(tx *sql.Tx, opts ...*resultSetOptions) (*ResultSet, error) {
+	qb = qb.Clone()
+	_opts := defaultResultSetOptions
+
+	if len(opts) > 0 && opts[0] != nil {
+		_opts = opts[0]
+	}
+
+	var rs *ResultSet
+
+	err := qb.queryForRows(tx, func(rows *sql.Rows) (bool, error) {
+		return true, rs.scanRow(rows, _opts)
+	}, func(rows *sql.Rows) error {
+		var err error
+		rs, err = newResultSet(rows)
+		return err
+	})
+
 	if err != nil {
-		writeLog("error", err)
+		return nil, err
 	}
 
-	return err
+	return rs, nil
 }
 
 func (qb *queryBuilder) getColumnValue(
@@ -808,11 +1373,14 @@ func (qb *queryBuilder) getIntValue(tx *sql.Tx, columnName string, defaultValue
 }
 
 func (qb *queryBuilder) count(tx *sql.Tx, countField string) (int, error) {
-	defer func() {
-		qb.timeout = 0
-	}()
+	qb = qb.Clone()
+
+	if err := qb.resolveScopes(); err != nil {
+		return 0, err
+	}
 
 	query, params := qb.buildCountSql(countField)
+	query = rebind(query)
 	logSql(query, params)
 	var err error
 
@@ -822,7 +1390,7 @@ func (qb *queryBuilder) count(tx *sql.Tx, countField string) (int, error) {
 		return 0, err
 	}
 
-	ctx, cancel := context.WithTimeout(context.TODO(), qb.getTimeout())
+	ctx, cancel := context.WithTimeout(qb.getContext(), qb.getTimeout())
 	defer cancel()
 	var rows *sql.Rows
 
@@ -859,11 +1427,14 @@ func (qb *queryBuilder) count(tx *sql.Tx, countField string) (int, error) {
 }
 
 func (qb *queryBuilder) sumForInt(tx *sql.Tx, fieldName string) (int, error) {
-	defer func() {
-		qb.timeout = 0
-	}()
+	qb = qb.Clone()
+
+	if err := qb.resolveScopes(); err != nil {
+		return 0, err
+	}
 
 	query, params := qb.buildSumSql(fieldName)
+	query = rebind(query)
 	logSql(query, params)
 	var err error
 
@@ -873,7 +1444,7 @@ func (qb *queryBuilder) sumForInt(tx *sql.Tx, fieldName string) (int, error) {
 		return 0, err
 	}
 
-	ctx, cancel := context.WithTimeout(context.TODO(), qb.getTimeout())
+	ctx, cancel := context.WithTimeout(qb.getContext(), qb.getTimeout())
 	defer cancel()
 	var rows *sql.Rows
 
@@ -910,11 +1481,14 @@ func (qb *queryBuilder) sumForInt(tx *sql.Tx, fieldName string) (int, error) {
 }
 
 func (qb *queryBuilder) sumForFloat(tx *sql.Tx, fieldName string) (float64, error) {
-	defer func() {
-		qb.timeout = 0
-	}()
+	qb = qb.Clone()
+
+	if err := qb.resolveScopes(); err != nil {
+		return 0, err
+	}
 
 	query, params := qb.buildSumSql(fieldName)
+	query = rebind(query)
 	logSql(query, params)
 	var err error
 
@@ -924,7 +1498,7 @@ func (qb *queryBuilder) sumForFloat(tx *sql.Tx, fieldName string) (float64, erro
 		return 0, err
 	}
 
-	ctx, cancel := context.WithTimeout(context.TODO(), qb.getTimeout())
+	ctx, cancel := context.WithTimeout(qb.getContext(), qb.getTimeout())
 	defer cancel()
 	var rows *sql.Rows
 
@@ -961,23 +1535,57 @@ func (qb *queryBuilder) sumForFloat(tx *sql.Tx, fieldName string) (float64, erro
 }
 
 func (qb *queryBuilder) insertByMap(tx *sql.Tx, data map[string]interface{}) (int64, error) {
-	defer func() {
-		qb.timeout = 0
-	}()
+	qb = qb.Clone()
+
+	if err := qb.resolveScopes(); err != nil {
+		return 0, err
+	}
+
+	query, params, err := qb.buildInsertSqlByMap(data)
+
+	if err != nil {
+		return 0, err
+	}
 
-	query, params := qb.buildInsertSqlByMap(data)
+	query = rebind(query)
 
 	if tx != nil {
-		return TxInsertBySql(tx, query, params, qb.getTimeout())
+		return TxInsertBySql(tx, query, params, qb.getTimeout(), qb.getContext())
 	}
 
-	return InsertBySql(query, params, qb.getTimeout())
+	return InsertBySql(query, params, qb.getTimeout(), qb.getContext())
+}
+
+func (qb *queryBuilder) upsertByMap(tx *sql.Tx, data map[string]interface{}, uniqueKeys ...string) (int64, error) {
+	qb = qb.Clone()
+
+	if len(uniqueKeys) < 1 {
+		if pkColumn := getPkColumnName(qb.tables[0].name); pkColumn != "" {
+			uniqueKeys = []string{pkColumn}
+		}
+	}
+
+	if err := qb.resolveScopes(); err != nil {
+		return 0, err
+	}
+
+	query, params, err := qb.buildInsertSqlByMap(data, uniqueKeys...)
+
+	if err != nil {
+		return 0, err
+	}
+
+	query = rebind(query)
+
+	if tx != nil {
+		return TxInsertBySql(tx, query, params, qb.getTimeout(), qb.getContext())
+	}
+
+	return InsertBySql(query, params, qb.getTimeout(), qb.getContext())
 }
 
 func (qb *queryBuilder) insertByModel(tx *sql.Tx, model interface{}) (int64, error) {
-	defer func() {
-		qb.timeout = 0
-	}()
+	qb = qb.Clone()
 
 	err1 := NewDbException("param [model] must be a struct pointer")
 
@@ -993,41 +1601,275 @@ func (qb *queryBuilder) insertByModel(tx *sql.Tx, model interface{}) (int64, err
 
 	rt = rt.Elem()
 	rv := reflect.ValueOf(model).Elem()
-	query, pkField, params := qb.buildInsertSqlByModel(rt, rv)
+
+	if err := callBeforeInsert(tx, model); err != nil {
+		return 0, err
+	}
+
+	if err := qb.resolveScopes(); err != nil {
+		return 0, err
+	}
+
+	query, pkField, params, err := qb.buildInsertSqlByModel(rt, rv)
+
+	if err != nil {
+		return 0, err
+	}
+
+	query = rebind(query)
 	var n1 int64
-	var err error
 
 	if tx != nil {
-		n1, err = TxInsertBySql(tx, query, params, qb.getTimeout())
+		n1, err = TxInsertBySql(tx, query, params, qb.getTimeout(), qb.getContext())
 	} else {
-		n1, err = InsertBySql(query, params, qb.getTimeout())
+		n1, err = InsertBySql(query, params, qb.getTimeout(), qb.getContext())
+	}
+
+	if err != nil {
+		return n1, err
 	}
 
-	if err == nil && n1 > 0 && pkField != "" {
-		rv.FieldByName(pkField).Set(reflect.ValueOf(n1))
+	if n1 > 0 && pkField != "" {
+		setPkValueToModel(getModelFieldByName(rv, pkField), n1)
 	}
 
+	return n1, callAfterInsert(tx, model)
+}
+
+func (qb *queryBuilder) batchInsertByModels(tx *sql.Tx, models interface{}) (int64, error) {
+	qb = qb.Clone()
+
+	items, err := collectModelValues(models)
+
+	if err != nil {
+		return 0, err
+	}
+
+	if len(items) < 1 {
+		return 0, nil
+	}
+
+	if err := qb.resolveScopes(); err != nil {
+		return 0, err
+	}
+
+	if tx != nil {
+		return qb.batchInsertModelValues(tx, items)
+	}
+
+	// the hooks get the transaction that does the inserts
+	var n1 int64
+
+	err = Transations(func(tx *sql.Tx) error {
+		var err error
+		n1, err = qb.batchInsertModelValues(tx, items)
+		return err
+	})
+
 	return n1, err
 }
 
+func (qb *queryBuilder) batchInsertModelValues(tx *sql.Tx, items []reflect.Value) (int64, error) {
+	rows := make([]map[string]interface{}, 0, len(items))
+	pkFieldsByType := map[reflect.Type]string{}
+	pkFields := make([]string, 0, len(items))
+	allGenerated := true
+
+	for _, rv := range items {
+		if err := callBeforeInsert(tx, rv.Addr().Interface()); err != nil {
+			return 0, err
+		}
+
+		rt := rv.Type()
+		pkField, ok := pkFieldsByType[rt]
+
+		if !ok {
+			pkField, _ = getModelPkField(qb.tables[0].name, rt, rv)
+			pkFieldsByType[rt] = pkField
+		}
+
+		data, generatedPkField, err := qb.buildInsertDataByModel(rt, rv)
+
+		if err != nil {
+			return 0, err
+		}
+
+		// rows that carry their own pk value get no id written back
+		if generatedPkField == "" {
+			pkField = ""
+			allGenerated = false
+		}
+
+		rows = append(rows, data)
+		pkFields = append(pkFields, pkField)
+	}
+
+	var n1 int64
+	var err error
+	mode := getBatchInsertIdMode(tx)
+
+	if mode == batchInsertIdModeConsecutive && !allGenerated {
+		mode = batchInsertIdModeRowByRow
+	}
+
+	switch mode {
+	case batchInsertIdModeReturning:
+		n1, err = qb.batchInsertReturning(tx, rows, items, pkFields)
+	case batchInsertIdModeConsecutive:
+		n1, err = qb.batchInsertConsecutive(tx, rows, items, pkFields)
+	default:
+		n1, err = qb.batchInsertRowByRow(tx, rows, items, pkFields)
+	}
+
+	if err != nil {
+		return n1, err
+	}
+
+	for _, rv := range items {
+		if err := callAfterInsert(tx, rv.Addr().Interface()); err != nil {
+			return n1, err
+		}
+	}
+
+	return n1, nil
+}
+
+func (qb *queryBuilder) batchInsertReturning(
+	tx *sql.Tx,
+	rows []map[string]interface{},
+	items []reflect.Value,
+	pkFields []string,
+) (int64, error) {
+	query, params, err := qb.buildBatchInsertSql(rows)
+
+	if err != nil {
+		return 0, err
+	}
+
+	query = rebind(query)
+	var list []map[string]interface{}
+
+	if tx != nil {
+		list, err = TxSelectBySql(tx, query, params, qb.getTimeout(), qb.getContext())
+	} else {
+		list, err = SelectBySql(query, params, qb.getTimeout(), qb.getContext())
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	for idx := range items {
+		if pkFields[idx] == "" {
+			continue
+		}
+
+		if len(list) != len(items) {
+			return 0, NewDbException(fmt.Sprintf("batch insert returned %d ids for %d models", len(list), len(items)))
+		}
+
+		for _, value := range list[idx] {
+			setPkValueToModel(getModelFieldByName(items[idx], pkFields[idx]), castx.ToInt64(value))
+		}
+	}
+
+	return int64(len(items)), nil
+}
+
+func (qb *queryBuilder) batchInsertConsecutive(
+	tx *sql.Tx,
+	rows []map[string]interface{},
+	items []reflect.Value,
+	pkFields []string,
+) (int64, error) {
+	query, params, err := qb.buildBatchInsertSql(rows)
+
+	if err != nil {
+		return 0, err
+	}
+
+	query = rebind(query)
+	var firstId int64
+
+	if tx != nil {
+		firstId, err = TxInsertBySql(tx, query, params, qb.getTimeout(), qb.getContext())
+	} else {
+		firstId, err = InsertBySql(query, params, qb.getTimeout(), qb.getContext())
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	if firstId > 0 {
+		step := getAutoIncrementStep(tx)
+
+		for idx, rv := range items {
+			if pkFields[idx] != "" {
+				setPkValueToModel(getModelFieldByName(rv, pkFields[idx]), firstId+int64(idx)*step)
+			}
+		}
+	}
+
+	return int64(len(items)), nil
+}
+
+func (qb *queryBuilder) batchInsertRowByRow(
+	tx *sql.Tx,
+	rows []map[string]interface{},
+	items []reflect.Value,
+	pkFields []string,
+) (int64, error) {
+	var n1 int64
+
+	for idx, data := range rows {
+		query, params, err := qb.buildInsertSqlByMap(data)
+
+		if err != nil {
+			return n1, err
+		}
+
+		query = rebind(query)
+		id, err := TxInsertBySql(tx, query, params, qb.getTimeout(), qb.getContext())
+
+		if err != nil {
+			return n1, err
+		}
+
+		if pkFields[idx] != "" && id > 0 {
+			setPkValueToModel(getModelFieldByName(items[idx], pkFields[idx]), id)
+		}
+
+		n1++
+	}
+
+	return n1, nil
+}
+
 func (qb *queryBuilder) updateByMap(tx *sql.Tx, data map[string]interface{}) (int64, error) {
-	defer func() {
-		qb.timeout = 0
-	}()
+	qb = qb.Clone()
+
+	if err := qb.resolveScopes(); err != nil {
+		return 0, err
+	}
 
-	query, params := qb.buildUpdateSqlByMap(data)
+	query, params, err := qb.buildUpdateSqlByMap(data)
+
+	if err != nil {
+		return 0, err
+	}
+
+	query = rebind(query)
 
 	if tx != nil {
-		return TxUpdateBySql(tx, query, params, qb.getTimeout())
+		return TxUpdateBySql(tx, query, params, qb.getTimeout(), qb.getContext())
 	}
 
-	return UpdateBySql(query, params, qb.getTimeout())
+	return UpdateBySql(query, params, qb.getTimeout(), qb.getContext())
 }
 
 func (qb *queryBuilder) updateByModel(tx *sql.Tx, model interface{}) (int64, error) {
-	defer func() {
-		qb.timeout = 0
-	}()
+	qb = qb.Clone()
 
 	err1 := NewDbException("param [model] must be a struct pointer")
 
@@ -1041,135 +1883,330 @@ func (qb *queryBuilder) updateByModel(tx *sql.Tx, model interface{}) (int64, err
 		return 0, err1
 	}
 
-	rt = rt.Elem()
+	if err := callBeforeUpdate(tx, model); err != nil {
+		return 0, err
+	}
+
+	return qb.doUpdateByModel(tx, model)
+}
+
+// doUpdateByModel writes model without running its BeforeUpdate hook, which the caller has already run.
+func (qb *queryBuilder) doUpdateByModel(tx *sql.Tx, model interface{}) (int64, error) {
+	rt := reflect.TypeOf(model).Elem()
 	rv := reflect.ValueOf(model).Elem()
 
-	query, params := qb.buildUpdateSqlByModel(rt, rv)
+	if err := qb.resolveScopes(); err != nil {
+		return 0, err
+	}
+
+	query, versionField, params, err := qb.buildUpdateSqlByModel(rt, rv)
+
+	if err != nil {
+		return 0, err
+	}
+
+	query = rebind(query)
+	var n1 int64
 
 	if tx != nil {
-		return TxUpdateBySql(tx, query, params, qb.getTimeout())
+		n1, err = TxUpdateBySql(tx, query, params, qb.getTimeout(), qb.getContext())
+	} else {
+		n1, err = UpdateBySql(query, params, qb.getTimeout(), qb.getContext())
 	}
 
-	return UpdateBySql(query, params, qb.getTimeout())
-}
+	if err != nil {
+		return n1, err
+	}
 
-func (qb *queryBuilder) delete(tx *sql.Tx) (int64, error) {
-	defer func() {
-		qb.timeout = 0
-	}()
+	if versionField != "" {
+		field := reflect.Indirect(getModelFieldByName(rv, versionField))
+		version := field.Convert(reflect.TypeOf(int64(0))).Int()
 
-	query, params := qb.buildDeleteSql()
+		if n1 < 1 {
+			return n1, NewStaleModelException(normalizeTableName(qb.tables[0].name), version)
+		}
 
-	if tx != nil {
-		return TxDeleteBySql(tx, query, params, qb.getTimeout())
+		field.Set(reflect.ValueOf(version + 1).Convert(field.Type()))
 	}
 
-	return DeleteBySql(query, params, qb.getTimeout())
+	return n1, callAfterUpdate(tx, model)
 }
 
-func (qb *queryBuilder) softDelete(tx *sql.Tx) (int64, error) {
-	defer func() {
-		qb.timeout = 0
-	}()
+func (qb *queryBuilder) deleteByModel(tx *sql.Tx, model interface{}) (int64, error) {
+	qb = qb.Clone()
 
-	map1 := map[string]interface{}{}
-	tableName := qb.tables[0].name
+	err1 := NewDbException("param [model] must be a struct pointer")
 
-	if strings.Contains(tableName, ".") {
-		tableName = substringAfter(tableName, ".")
+	if model == nil {
+		return 0, err1
 	}
 
-	tableName = strings.ReplaceAll(tableName, "`", "")
+	rt := reflect.TypeOf(model)
 
-	schemas, ok := tableSchemas[tableName]
+	if rt.Kind() != reflect.Ptr || rt.Elem().Kind() != reflect.Struct {
+		return 0, err1
+	}
 
-	if !ok {
-		return 0, nil
+	tableName := qb.tables[0].name
+	pkField, pkValue := getModelPkField(tableName, rt.Elem(), reflect.ValueOf(model).Elem())
+
+	if pkField == "" || !pkValue.IsValid() || pkValue.IsZero() {
+		return 0, NewDbException("in dbx.DeleteByModel function, primary key of model is empty")
 	}
 
-	fieldNames := []string{
-		"del_flag",
-		"delFlag",
+	if err := callBeforeDelete(tx, model); err != nil {
+		return 0, err
 	}
 
-	for _, item := range schemas {
-		if slicex.InStringSlice(item.FieldName, fieldNames) && strings.Contains(item.FieldType, "int") {
-			map1[item.FieldName] = 1
+	pkColumn := firstNonEmpty(getPkColumnName(tableName), "id")
+
+	for _, mf := range getModelFields(rt) {
+		if mf.name == pkField {
+			pkColumn = getModelFieldColumnName(tableName, mf)
 			break
 		}
 	}
 
-	if len(map1) < 1 {
-		fieldNames := []string{
-			"delete_at",
-			"deleteAt",
+	qb.conditions = []string{}
+	qb.bindValues = []interface{}{}
+	qb.Where(pkColumn, pkValue.Interface())
+	return qb.delete(tx)
+}
+
+func (qb *queryBuilder) save(tx *sql.Tx, model interface{}) (int64, error) {
+	qb = qb.Clone()
+	err1 := NewDbException("param [model] must be a struct pointer")
+
+	if model == nil {
+		return 0, err1
+	}
+
+	rt := reflect.TypeOf(model)
+
+	if rt.Kind() != reflect.Ptr || rt.Elem().Kind() != reflect.Struct {
+		return 0, err1
+	}
+
+	rt = rt.Elem()
+	rv := reflect.ValueOf(model).Elem()
+	pkField, pkValue := getModelPkField(qb.tables[0].name, rt, rv)
+
+	if pkField == "" || !pkValue.IsValid() || pkValue.IsZero() {
+		n1, err := qb.insertByModel(tx, model)
+
+		if err == nil {
+			rememberOriginalValues(model)
 		}
 
-		for _, item := range schemas {
-			if slicex.InStringSlice(item.FieldName, fieldNames) && item.FieldType == "datetime" {
-				map1[item.FieldName] = time.Now().Format(dateFormatFull)
-			}
+		return n1, err
+	}
+
+	// the hook runs before the dirty set is taken, so fields it sets are written too
+	if err := callBeforeUpdate(tx, model); err != nil {
+		return 0, err
+	}
+
+	if dirtyFields, tracked := getDirtyFields(model); tracked {
+		dirtyFields = qb.filterWritableFields(rt, dirtyFields)
+
+		if len(dirtyFields) < 1 {
+			return 0, nil
+		}
+
+		qb.includeFields = dirtyFields
+	}
+
+	n1, err := qb.doUpdateByModel(tx, model)
+
+	if err == nil {
+		rememberOriginalValues(model)
+	}
+
+	return n1, err
+}
+
+// filterWritableFields drops the fields an update by model never writes: the primary key, the
+// version column, readonly and excluded fields.
+func (qb *queryBuilder) filterWritableFields(rt reflect.Type, fieldNames []string) []string {
+	tableName := qb.tables[0].name
+	list := make([]string, 0, len(fieldNames))
+
+	for _, mf := range getModelFields(rt) {
+		if !inStringSlice(mf.name, fieldNames) || mf.readonly || inStringSlice(mf.name, qb.excludeFields) {
+			continue
+		}
+
+		columnName := getModelFieldColumnName(tableName, mf)
+
+		if columnName == "" || mf.isPk(tableName, columnName) || isVersionField(tableName, columnName, mf.field) {
+			continue
+		}
+
+		list = append(list, mf.name)
+	}
+
+	return list
+}
+
+func (qb *queryBuilder) incr(tx *sql.Tx, fieldName string, num interface{}, operator string) (int64, error) {
+	var value interface{}
+
+	if n1, ok := num.(float64); ok && n1 > 0 {
+		value = n1
+	} else if n1, ok := num.(float32); ok && n1 > 0 {
+		value = float64(n1)
+	} else if s1, ok := num.(string); ok && s1 != "" {
+		n1, err := strconv.ParseFloat(s1, 64)
+
+		if err != nil || n1 < 0 {
+			return 0, nil
 		}
+
+		value = n1
+	} else if n1, err := strconv.Atoi(toString(num)); err == nil && n1 > 0 {
+		value = n1
+	} else {
+		return 0, nil
 	}
 
+	data := map[string]interface{}{fieldName: Raw(quote(fieldName)+" "+operator+" ?", value)}
+	return qb.updateByMap(tx, data)
+}
+
+func (qb *queryBuilder) delete(tx *sql.Tx) (int64, error) {
+	if len(qb.tables) > 0 && SoftDeleteScopeEnabled() {
+		if _, _, ok := getSoftDeleteField(qb.tables[0].name); ok {
+			return qb.softDelete(tx)
+		}
+	}
+
+	return qb.forceDelete(tx)
+}
+
+func (qb *queryBuilder) forceDelete(tx *sql.Tx) (int64, error) {
+	qb = qb.Clone()
+	qb.trashedMode = trashedModeWith
+
+	if err := qb.resolveScopes(); err != nil {
+		return 0, err
+	}
+
+	query, params, err := qb.buildDeleteSql()
+
+	if err != nil {
+		return 0, err
+	}
+
+	query = rebind(query)
+
+	if tx != nil {
+		return TxDeleteBySql(tx, query, params, qb.getTimeout(), qb.getContext())
+	}
+
+	return DeleteBySql(query, params, qb.getTimeout(), qb.getContext())
+}
+
+func (qb *queryBuilder) softDelete(tx *sql.Tx) (int64, error) {
+	qb = qb.Clone()
+
+	map1 := buildSoftDeleteData(qb.tables[0].name)
+
 	if len(map1) < 1 {
 		return 0, nil
 	}
 
-	query, params := qb.buildUpdateSqlByMap(map1)
+	if err := qb.resolveScopes(); err != nil {
+		return 0, err
+	}
+
+	query, params, err := qb.buildUpdateSqlByMap(map1)
+
+	if err != nil {
+		return 0, err
+	}
+
+	query = rebind(query)
 
 	if tx != nil {
-		return TxUpdateBySql(tx, query, params, qb.getTimeout())
+		return TxUpdateBySql(tx, query, params, qb.getTimeout(), qb.getContext())
 	}
 
-	return UpdateBySql(query, params, qb.getTimeout())
+	return UpdateBySql(query, params, qb.getTimeout(), qb.getContext())
 }
 
-func (qb *queryBuilder) handleDatetimeFieldInModel(tableName, columnName string, t1 *time.Time) string {
-	schemas := tableSchemas[tableName]
+func (qb *queryBuilder) restore(tx *sql.Tx) (int64, error) {
+	qb = qb.Clone()
+
+	field, isFlag, ok := getSoftDeleteField(qb.tables[0].name)
 
-	if len(schemas) < 1 {
-		return "NotExists"
+	if !ok {
+		return 0, nil
 	}
 
-	var fieldInfo tableFieldInfo
+	map1 := map[string]interface{}{}
 
-	for _, item := range schemas {
+	if isFlag {
+		map1[field.FieldName] = 0
+	} else if getTimeColumnKind(field) == "unix" && !field.Nullable {
+		map1[field.FieldName] = 0
+	} else {
+		map1[field.FieldName] = nil
+	}
+
+	qb.trashedMode = trashedModeOnly
+
+	if err := qb.resolveScopes(); err != nil {
+		return 0, err
+	}
+
+	query, params, err := qb.buildUpdateSqlByMap(map1)
+
+	if err != nil {
+		return 0, err
+	}
+
+	query = rebind(query)
+
+	if tx != nil {
+		return TxUpdateBySql(tx, query, params, qb.getTimeout(), qb.getContext())
+	}
+
+	return UpdateBySql(query, params, qb.getTimeout(), qb.getContext())
+}
+
+func (qb *queryBuilder) handleDatetimeFieldInModel(tableName, columnName string, t1 *time.Time) (interface{}, bool) {
+	var fieldInfo TableFieldInfo
+
+	for _, item := range getTableFields(tableName) {
 		if item.FieldName == columnName {
 			fieldInfo = item
 			break
 		}
 	}
 
-	if fieldInfo.FieldName != columnName {
-		return "NotExists"
+	if fieldInfo.FieldName != columnName || getTimeColumnKind(fieldInfo) == "" {
+		return nil, false
 	}
 
-	if strings.Contains(fieldInfo.FieldType, "datetime") {
-		if t1 == nil {
-			if fieldInfo.Nullable {
-				return "nil"
-			}
-
-			return "NotNullable"
+	if t1 == nil || t1.IsZero() {
+		if fieldInfo.Nullable {
+			return nil, true
 		}
 
-		return t1.Format(dateFormatFull)
-	}
-
-	if strings.Contains(fieldInfo.FieldType, "date") {
 		if t1 == nil {
-			if fieldInfo.Nullable {
-				return "nil"
-			}
-
-			return "NotNullable"
+			return nil, false
 		}
+	}
+
+	return getConventions(tableName).timeValueForField(fieldInfo, t1)
+}
 
-		return t1.Format(dateFormatDateOnly)
+func (qb *queryBuilder) getContext() context.Context {
+	if qb.ctx == nil {
+		return context.TODO()
 	}
 
-	return "NotMatched"
+	return qb.ctx
 }
 
 func (qb *queryBuilder) getTimeout() time.Duration {
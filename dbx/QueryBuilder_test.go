@@ -0,0 +1,75 @@
+package dbx
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCloneIsIndependent(t *testing.T) {
+	qb := Table("orders").Where("status", 1).OrderBy("id").Limit(10)
+	want, wantParams := qb.buildSelectSql()
+
+	c := qb.Clone()
+	c.Where("user_id", 2).OrderBy("created_at").Limit(5).Join("users u", "u.id = orders.user_id")
+
+	if query, params := qb.buildSelectSql(); query != want || !reflect.DeepEqual(params, wantParams) {
+		t.Fatalf("clone changed original: %q %v, want %q %v", query, params, want, wantParams)
+	}
+
+	if query, _ := c.buildSelectSql(); query == want {
+		t.Fatal("clone did not get its own conditions")
+	}
+}
+
+func TestMapMutationSqlIsStable(t *testing.T) {
+	data := func() map[string]interface{} {
+		return map[string]interface{}{"b": 2, "a": 1, "d": nil, "c": Raw("NOW()")}
+	}
+
+	qb := Table("orders")
+	insert, insertParams, err := qb.buildInsertSqlByMap(data(), "a")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	update, updateParams, _ := Table("orders").Where("id", 1).buildUpdateSqlByMap(data())
+
+	for i := 0; i < 20; i++ {
+		query, params, _ := qb.buildInsertSqlByMap(data(), "a")
+
+		if query != insert || !reflect.DeepEqual(params, insertParams) {
+			t.Fatalf("insert sql changed between runs: %q, want %q", query, insert)
+		}
+
+		query, params, _ = Table("orders").Where("id", 1).buildUpdateSqlByMap(data())
+
+		if query != update || !reflect.DeepEqual(params, updateParams) {
+			t.Fatalf("update sql changed between runs: %q, want %q", query, update)
+		}
+	}
+}
+
+func TestExecutorsLeaveBuilderUnchanged(t *testing.T) {
+	openSqliteTestDb(t,
+		"CREATE TABLE orders (id INTEGER PRIMARY KEY AUTOINCREMENT, status INTEGER)",
+		"INSERT INTO orders (status) VALUES (1), (1), (2)",
+	)
+
+	qb := Table("orders").Where("status", 1).Limit(1)
+	want, wantParams := qb.buildSelectSql()
+
+	for i := 0; i < 2; i++ {
+		if n, err := qb.Count(); err != nil || n != 2 {
+			t.Fatalf("Count() = %d, %v, want 2", n, err)
+		}
+
+		if list, err := qb.Get(); err != nil || len(list) != 1 {
+			t.Fatalf("Get() = %v, %v, want 1 row", list, err)
+		}
+	}
+
+	if query, params := qb.buildSelectSql(); query != want || !reflect.DeepEqual(params, wantParams) {
+		t.Fatalf("executors changed builder: %q %v, want %q %v", query, params, want, wantParams)
+	}
+}
@@ -0,0 +1,425 @@
+package dbx
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"github.com/meiguonet/mgboot-go-common/util/castx"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const (
+	relationHasOne     = "has_one"
+	relationHasMany    = "has_many"
+	relationBelongsTo  = "belongs_to"
+	relationManyToMany = "many_to_many"
+)
+
+type relation struct {
+	kind         string
+	table        string
+	foreignKey   string
+	localKey     string
+	ownerKey     string
+	pivot        string
+	pivotLocal   string
+	pivotForeign string
+}
+
+func parseRelationTag(tag reflect.StructTag) (relation, bool) {
+	s1, ok := tag.Lookup("rel")
+
+	if !ok || s1 == "" {
+		return relation{}, false
+	}
+
+	parts := strings.Split(s1, ",")
+	rel := relation{kind: strings.TrimSpace(parts[0])}
+
+	for _, part := range parts[1:] {
+		part = strings.TrimSpace(part)
+
+		if !strings.Contains(part, "=") {
+			continue
+		}
+
+		value := strings.TrimSpace(substringAfter(part, "="))
+
+		switch strings.TrimSpace(substringBefore(part, "=")) {
+		case "table":
+			rel.table = value
+		case "foreign_key":
+			rel.foreignKey = value
+		case "local_key":
+			rel.localKey = value
+		case "owner_key":
+			rel.ownerKey = value
+		case "pivot":
+			rel.pivot = value
+		case "pivot_local":
+			rel.pivotLocal = value
+		case "pivot_foreign":
+			rel.pivotForeign = value
+		}
+	}
+
+	switch rel.kind {
+	case relationHasOne, relationHasMany, relationBelongsTo, relationManyToMany:
+	default:
+		return relation{}, false
+	}
+
+	return rel, rel.table != ""
+}
+
+func (qb *queryBuilder) With(relations ...string) *queryBuilder {
+	for _, name := range relations {
+		name = strings.TrimSpace(name)
+
+		if name != "" && !inStringSlice(name, qb.withRelations) {
+			qb.withRelations = append(qb.withRelations, name)
+		}
+	}
+
+	return qb
+}
+
+func (qb *queryBuilder) loadRelations(tx *sql.Tx, tableName string, parents []reflect.Value, paths []string) error {
+	if len(parents) < 1 || len(paths) < 1 {
+		return nil
+	}
+
+	names := make([]string, 0)
+	nested := map[string][]string{}
+
+	for _, path := range paths {
+		name := path
+		var rest string
+
+		if strings.Contains(path, ".") {
+			name = substringBefore(path, ".")
+			rest = substringAfter(path, ".")
+		}
+
+		if !inStringSlice(name, names) {
+			names = append(names, name)
+		}
+
+		if rest != "" {
+			nested[name] = append(nested[name], rest)
+		}
+	}
+
+	rt := parents[0].Type()
+
+	for _, name := range names {
+		field, ok := rt.FieldByName(name)
+
+		if !ok {
+			return NewDbException(fmt.Sprintf("relation [%s] not found in %v", name, rt))
+		}
+
+		rel, ok := parseRelationTag(field.Tag)
+
+		if !ok {
+			return NewDbException(fmt.Sprintf("field [%s] of %v has no valid rel tag", name, rt))
+		}
+
+		children, err := qb.loadRelation(tx, tableName, parents, field, rel)
+
+		if err != nil {
+			return err
+		}
+
+		if err := qb.loadRelations(tx, rel.table, children, nested[name]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (qb *queryBuilder) loadRelation(
+	tx *sql.Tx,
+	tableName string,
+	parents []reflect.Value,
+	field reflect.StructField,
+	rel relation,
+) ([]reflect.Value, error) {
+	relatedType := field.Type
+
+	if relatedType.Kind() == reflect.Slice {
+		relatedType = relatedType.Elem()
+	}
+
+	if relatedType.Kind() == reflect.Ptr {
+		relatedType = relatedType.Elem()
+	}
+
+	if relatedType.Kind() != reflect.Struct {
+		return nil, NewDbException(fmt.Sprintf("relation field [%s] must be a struct, struct pointer or slice of them", field.Name))
+	}
+
+	var parentKey, relatedKey string
+
+	switch rel.kind {
+	case relationHasOne, relationHasMany:
+		parentKey = firstNonEmpty(rel.localKey, getPkColumnName(tableName), "id")
+		relatedKey = rel.foreignKey
+	case relationBelongsTo:
+		parentKey = rel.foreignKey
+		relatedKey = firstNonEmpty(rel.ownerKey, getPkColumnName(rel.table), "id")
+	case relationManyToMany:
+		parentKey = firstNonEmpty(rel.localKey, getPkColumnName(tableName), "id")
+		relatedKey = firstNonEmpty(rel.ownerKey, getPkColumnName(rel.table), "id")
+	}
+
+	if parentKey == "" || relatedKey == "" {
+		return nil, NewDbException(fmt.Sprintf("relation [%s] requires foreign_key", field.Name))
+	}
+
+	parentKeys := make([]interface{}, 0, len(parents))
+	parentKeyValues := make([]string, len(parents))
+	seen := make(map[string]bool, len(parents))
+
+	for idx, parent := range parents {
+		value, ok := getModelColumnValue(parent, tableName, parentKey)
+
+		if !ok {
+			continue
+		}
+
+		value, key, ok := toRelationKey(value)
+
+		if !ok || key == "0" {
+			continue
+		}
+
+		parentKeyValues[idx] = key
+
+		if !seen[key] {
+			seen[key] = true
+			parentKeys = append(parentKeys, value)
+		}
+	}
+
+	pivotMap := map[string][]string{}
+
+	if rel.kind == relationManyToMany {
+		if rel.pivot == "" || rel.pivotLocal == "" || rel.pivotForeign == "" {
+			return nil, NewDbException(fmt.Sprintf("relation [%s] requires pivot, pivot_local and pivot_foreign", field.Name))
+		}
+
+		var err error
+		pivotMap, parentKeys, err = qb.loadPivotKeys(tx, rel, parentKeys)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	related := reflect.New(reflect.SliceOf(reflect.PtrTo(relatedType)))
+
+	if len(parentKeys) > 0 {
+		err := qb.newRelationQuery(rel.table).WhereIn(relatedKey, parentKeys).getInto(tx, related.Interface())
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	groups := map[string][]reflect.Value{}
+	items := related.Elem()
+
+	for i := 0; i < items.Len(); i++ {
+		item := items.Index(i).Elem()
+		value, ok := getModelColumnValue(item, rel.table, relatedKey)
+
+		if !ok {
+			continue
+		}
+
+		_, key, ok := toRelationKey(value)
+
+		if !ok {
+			continue
+		}
+
+		groups[key] = append(groups[key], item)
+	}
+
+	children := make([]reflect.Value, 0, items.Len())
+
+	for idx, parent := range parents {
+		key := parentKeyValues[idx]
+		var matched []reflect.Value
+
+		if rel.kind == relationManyToMany {
+			for _, relatedKeyValue := range pivotMap[key] {
+				matched = append(matched, groups[relatedKeyValue]...)
+			}
+		} else if key != "" {
+			matched = groups[key]
+		}
+
+		fv := parent.FieldByIndex(field.Index)
+		children = append(children, attachRelated(fv, matched)...)
+	}
+
+	return children, nil
+}
+
+func (qb *queryBuilder) loadPivotKeys(
+	tx *sql.Tx,
+	rel relation,
+	parentKeys []interface{},
+) (map[string][]string, []interface{}, error) {
+	pivotMap := map[string][]string{}
+	relatedKeys := make([]interface{}, 0)
+	seen := map[string]bool{}
+
+	if len(parentKeys) < 1 {
+		return pivotMap, relatedKeys, nil
+	}
+
+	list, err := qb.newRelationQuery(rel.pivot).
+		WhereIn(rel.pivotLocal, parentKeys).
+		getForMapList(tx, []string{rel.pivotLocal, rel.pivotForeign})
+
+	if err != nil {
+		return pivotMap, relatedKeys, err
+	}
+
+	for _, item := range list {
+		_, localKey, ok1 := toRelationKey(item[rel.pivotLocal])
+		foreignValue, foreignKey, ok2 := toRelationKey(item[rel.pivotForeign])
+
+		if !ok1 || !ok2 {
+			continue
+		}
+
+		pivotMap[localKey] = append(pivotMap[localKey], foreignKey)
+
+		if !seen[foreignKey] {
+			seen[foreignKey] = true
+			relatedKeys = append(relatedKeys, foreignValue)
+		}
+	}
+
+	return pivotMap, relatedKeys, nil
+}
+
+func (qb *queryBuilder) newRelationQuery(tableName string) *queryBuilder {
+	related := Table(tableName)
+	related.ctx = qb.ctx
+	related.timeout = qb.timeout
+	related.withoutScopes = append(related.withoutScopes, qb.withoutScopes...)
+
+	// children of trashed parents are usually trashed along with them, so OnlyTrashed widens to
+	// WithTrashed rather than hiding the live children of a trashed parent
+	if qb.trashedMode != trashedModeWithout {
+		related.trashedMode = trashedModeWith
+	}
+
+	return related
+}
+
+func attachRelated(fv reflect.Value, matched []reflect.Value) []reflect.Value {
+	children := make([]reflect.Value, 0, len(matched))
+
+	if !fv.CanSet() {
+		return children
+	}
+
+	if fv.Kind() != reflect.Slice {
+		if len(matched) < 1 {
+			fv.Set(reflect.Zero(fv.Type()))
+			return children
+		}
+
+		if fv.Kind() == reflect.Ptr {
+			ptr := reflect.New(fv.Type().Elem())
+			ptr.Elem().Set(matched[0])
+			fv.Set(ptr)
+			return append(children, ptr.Elem())
+		}
+
+		fv.Set(matched[0])
+		return append(children, fv)
+	}
+
+	elemType := fv.Type().Elem()
+	list := reflect.MakeSlice(fv.Type(), len(matched), len(matched))
+
+	for idx, item := range matched {
+		if elemType.Kind() == reflect.Ptr {
+			ptr := reflect.New(elemType.Elem())
+			ptr.Elem().Set(item)
+			list.Index(idx).Set(ptr)
+			children = append(children, ptr.Elem())
+		} else {
+			list.Index(idx).Set(item)
+			children = append(children, list.Index(idx))
+		}
+	}
+
+	fv.Set(list)
+	return children
+}
+
+func getModelColumnValue(rv reflect.Value, tableName, columnName string) (interface{}, bool) {
+	for _, mf := range getModelFields(rv.Type()) {
+		name := getModelFieldColumnName(tableName, mf)
+
+		if !strings.EqualFold(stripColumnSeparators(name), stripColumnSeparators(columnName)) {
+			continue
+		}
+
+		fv, ok := resolveModelField(rv, mf, false)
+
+		if !ok {
+			return nil, false
+		}
+
+		return indirect(fv.Interface()), true
+	}
+
+	return nil, false
+}
+
+func toRelationKey(value interface{}) (interface{}, string, bool) {
+	if value == nil {
+		return nil, "", false
+	}
+
+	value, err := driver.DefaultParameterConverter.ConvertValue(value)
+
+	if err != nil {
+		return nil, "", false
+	}
+
+	switch v := value.(type) {
+	case int64:
+		return v, strconv.FormatInt(v, 10), true
+	case float64:
+		return v, castx.ToString(v), true
+	case string:
+		return v, v, v != ""
+	case []byte:
+		return string(v), string(v), len(v) > 0
+	}
+
+	return nil, "", false
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,167 @@
+package dbx
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+
+	"github.com/meiguonet/mgboot-go-common/logx"
+)
+
+type relUser struct {
+	Id    int64      `db:"id,pk"`
+	Name  string     `db:"name"`
+	Posts []*relPost `rel:"has_many,table=posts,foreign_key=user_id"`
+}
+
+type relPost struct {
+	Id     int64    `db:"id,pk"`
+	UserId int64    `db:"user_id"`
+	Title  string   `db:"title"`
+	Author *relUser `rel:"belongs_to,table=users,foreign_key=user_id"`
+}
+
+// sqlRecorder keeps the statements logged in debug mode.
+type sqlRecorder struct {
+	logx.Logger
+	statements []string
+}
+
+func (r *sqlRecorder) Debug(args ...interface{}) {
+	if s1, ok := args[0].(string); ok && !strings.HasPrefix(s1, "params: ") {
+		r.statements = append(r.statements, s1)
+	}
+}
+
+func (r *sqlRecorder) Log(level interface{}, args ...interface{}) {
+}
+
+func openRelationTestDb(t *testing.T) {
+	openSqliteTestDb(t,
+		"CREATE TABLE users (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT)",
+		"CREATE TABLE posts (id INTEGER PRIMARY KEY AUTOINCREMENT, user_id INTEGER, title TEXT, "+
+			"del_flag INTEGER NOT NULL DEFAULT 0)",
+		"INSERT INTO users (name) VALUES ('ann'), ('bob'), ('cid')",
+		"INSERT INTO posts (user_id, title, del_flag) VALUES (1, 'a1', 0), (1, 'a2', 0), (2, 'b1', 0), (2, 'b2', 1)",
+	)
+}
+
+type testUserID int64
+
+func TestToRelationKey(t *testing.T) {
+	id := testUserID(7)
+
+	cases := []struct {
+		value interface{}
+		want  interface{}
+		key   string
+		ok    bool
+	}{
+		{int(3), int64(3), "3", true},
+		{testUserID(5), int64(5), "5", true},
+		{&id, int64(7), "7", true},
+		{sql.NullInt64{Int64: 9, Valid: true}, int64(9), "9", true},
+		{sql.NullInt64{}, nil, "", false},
+		{sql.NullString{String: "a1", Valid: true}, "a1", "a1", true},
+		{[]byte("k"), "k", "k", true},
+		{"", "", "", false},
+		{nil, nil, "", false},
+		{struct{}{}, nil, "", false},
+	}
+
+	for _, c := range cases {
+		value, key, ok := toRelationKey(c.value)
+
+		if value != c.want || key != c.key || ok != c.ok {
+			t.Errorf("toRelationKey(%#v) = %#v, %q, %v, want %#v, %q, %v", c.value, value, key, ok, c.want, c.key, c.ok)
+		}
+	}
+}
+
+func TestWithHasManyBatchesChildren(t *testing.T) {
+	openRelationTestDb(t)
+	recorder := &sqlRecorder{}
+	WithLogger(recorder)
+	DebugModeEnabled(true)
+
+	defer func() {
+		DebugModeEnabled(false)
+		WithLogger(nil)
+	}()
+
+	var users []*relUser
+
+	if err := Table("users").With("Posts").OrderBy("id").GetInto(&users); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(users) != 3 || len(users[0].Posts) != 2 || len(users[1].Posts) != 1 || len(users[2].Posts) != 0 {
+		t.Fatalf("unexpected posts per user")
+	}
+
+	if users[1].Posts[0].Title != "b1" {
+		t.Fatalf("trashed post loaded: %+v", users[1].Posts[0])
+	}
+
+	if len(recorder.statements) != 2 || !strings.Contains(recorder.statements[1], `"user_id" IN (?, ?, ?)`) {
+		t.Fatalf("statements = %q, want one batched IN query for posts", recorder.statements)
+	}
+}
+
+func TestWithBelongsTo(t *testing.T) {
+	openRelationTestDb(t)
+	var posts []*relPost
+
+	if err := Table("posts").With("Author").OrderBy("id").GetInto(&posts); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(posts) != 3 {
+		t.Fatalf("got %d posts, want 3", len(posts))
+	}
+
+	for _, p := range posts {
+		if p.Author == nil || p.Author.Id != p.UserId {
+			t.Fatalf("post %d has author %+v", p.Id, p.Author)
+		}
+	}
+
+	if posts[0].Author == posts[2].Author || posts[2].Author.Name != "bob" {
+		t.Fatal("authors attached to the wrong posts")
+	}
+}
+
+func TestWithEmptyParents(t *testing.T) {
+	openRelationTestDb(t)
+	recorder := &sqlRecorder{}
+	WithLogger(recorder)
+	DebugModeEnabled(true)
+
+	defer func() {
+		DebugModeEnabled(false)
+		WithLogger(nil)
+	}()
+
+	var users []*relUser
+
+	if err := Table("users").Where("id", 99).With("Posts").GetInto(&users); err != nil || len(users) != 0 {
+		t.Fatalf("GetInto() = %d users, %v", len(users), err)
+	}
+
+	if len(recorder.statements) != 1 {
+		t.Fatalf("statements = %q, want no relation query", recorder.statements)
+	}
+}
+
+func TestWithTrashedLoadsTrashedChildren(t *testing.T) {
+	openRelationTestDb(t)
+	var user relUser
+
+	if err := Table("users").Where("id", 2).WithTrashed().With("Posts").FirstInto(&user); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(user.Posts) != 2 {
+		t.Fatalf("got %d posts, want the trashed one too", len(user.Posts))
+	}
+}
@@ -0,0 +1,607 @@
+package dbx
+
+import (
+	"context"
+	"database/sql"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DecimalAsString = iota
+	DecimalAsFloat64
+)
+
+const (
+	BitAsUint64 = iota
+	BitAsBool
+	BitAsBytes
+)
+
+const (
+	BinaryAsBytes = iota
+	BinaryAsString
+)
+
+const (
+	UnsignedAsUint64 = iota
+	UnsignedAsString
+)
+
+// TinyIntAsBool reads every TINYINT column as bool. It is meant for mysql, whose driver reports
+// neither the display width nor the declared type, so TINYINT(1) cannot be told apart otherwise.
+const (
+	TinyIntAsInt64 = iota
+	TinyIntAsBool
+)
+
+type resultSetOptions struct {
+	decimalMode  int
+	bitMode      int
+	binaryMode   int
+	unsignedMode int
+	tinyIntMode  int
+}
+
+var defaultResultSetOptions = NewResultSetOptions()
+
+func NewResultSetOptions() *resultSetOptions {
+	return &resultSetOptions{}
+}
+
+func (o *resultSetOptions) WithDecimalMode(mode int) *resultSetOptions {
+	o.decimalMode = mode
+	return o
+}
+
+func (o *resultSetOptions) WithBitMode(mode int) *resultSetOptions {
+	o.bitMode = mode
+	return o
+}
+
+func (o *resultSetOptions) WithBinaryMode(mode int) *resultSetOptions {
+	o.binaryMode = mode
+	return o
+}
+
+func (o *resultSetOptions) WithUnsignedMode(mode int) *resultSetOptions {
+	o.unsignedMode = mode
+	return o
+}
+
+func (o *resultSetOptions) WithTinyIntMode(mode int) *resultSetOptions {
+	o.tinyIntMode = mode
+	return o
+}
+
+func WithResultSetOptions(opts *resultSetOptions) {
+	if opts != nil {
+		defaultResultSetOptions = opts
+	}
+}
+
+type ColumnInfo struct {
+	Name             string
+	DatabaseTypeName string
+	Nullable         bool
+	NullableKnown    bool
+	Precision        int64
+	Scale            int64
+	DecimalSizeKnown bool
+	Length           int64
+	ScanType         reflect.Type
+}
+
+type Row struct {
+	columns []ColumnInfo
+	index   map[string]int
+	values  []interface{}
+}
+
+type ResultSet struct {
+	Columns []ColumnInfo
+	Rows    []*Row
+	index   map[string]int
+}
+
+func QueryResultSet(query string, args ...interface{}) (*ResultSet, error) {
+	return doQueryResultSet(nil, query, args...)
+}
+
+func TxQueryResultSet(tx *sql.Tx, query string, args ...interface{}) (*ResultSet, error) {
+	return doQueryResultSet(tx, query, args...)
+}
+
+func doQueryResultSet(tx *sql.Tx, query string, args ...interface{}) (*ResultSet, error) {
+	if pool == nil {
+		err := NewDbException("database connection pool is nil")
+		writeLog("error", err)
+		return nil, err
+	}
+
+	opts := defaultResultSetOptions
+
+	for _, arg := range args {
+		if o, ok := arg.(*resultSetOptions); ok && o != nil {
+			opts = o
+		}
+	}
+
+	params, timeout, parentCtx := getParamsTimeoutAndContext(args)
+
+	if timeout < time.Second {
+		timeout = 5 * time.Second
+	}
+
+	logSql(query, params)
+	ctx, cancel := context.WithTimeout(parentCtx, timeout)
+	defer cancel()
+	var rows *sql.Rows
+	var err error
+
+	if tx != nil {
+		rows, err = tx.QueryContext(ctx, query, params...)
+	} else {
+		rows, err = pool.QueryContext(ctx, query, params...)
+	}
+
+	if err != nil {
+		writeLog("error", err)
+		return nil, toDbException(err)
+	}
+
+	defer rows.Close()
+	rs, err := scanIntoResultSet(rows, opts)
+
+	if err != nil {
+		writeLog("error", err)
+		return nil, toDbException(err)
+	}
+
+	return rs, nil
+}
+
+func scanIntoResultSet(rows *sql.Rows, opts *resultSetOptions) (*ResultSet, error) {
+	rs, err := newResultSet(rows)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for rows.Next() {
+		if err := rs.scanRow(rows, opts); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return rs, nil
+}
+
+func newResultSet(rows *sql.Rows) (*ResultSet, error) {
+	columnTypes, err := rows.ColumnTypes()
+
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make([]ColumnInfo, len(columnTypes))
+	index := make(map[string]int, len(columnTypes))
+
+	for idx, ct := range columnTypes {
+		col := ColumnInfo{
+			Name:             ct.Name(),
+			DatabaseTypeName: strings.ToUpper(ct.DatabaseTypeName()),
+			ScanType:         ct.ScanType(),
+		}
+
+		col.Nullable, col.NullableKnown = ct.Nullable()
+		col.Precision, col.Scale, col.DecimalSizeKnown = ct.DecimalSize()
+		col.Length, _ = ct.Length()
+		columns[idx] = col
+
+		if _, ok := index[col.Name]; !ok {
+			index[col.Name] = idx
+		}
+	}
+
+	return &ResultSet{Columns: columns, Rows: make([]*Row, 0), index: index}, nil
+}
+
+func (rs *ResultSet) scanRow(rows *sql.Rows, opts *resultSetOptions) error {
+	raw := make([]interface{}, len(rs.Columns))
+	scanArgs := make([]interface{}, len(rs.Columns))
+
+	for idx := range raw {
+		scanArgs[idx] = &raw[idx]
+	}
+
+	if err := rows.Scan(scanArgs...); err != nil {
+		return err
+	}
+
+	values := make([]interface{}, len(rs.Columns))
+
+	for idx, col := range rs.Columns {
+		values[idx] = convertColumnValue(col, raw[idx], opts)
+	}
+
+	rs.Rows = append(rs.Rows, &Row{columns: rs.Columns, index: rs.index, values: values})
+	return nil
+}
+
+func convertColumnValue(col ColumnInfo, raw interface{}, opts *resultSetOptions) interface{} {
+	if raw == nil {
+		return nil
+	}
+
+	if buf, ok := raw.([]byte); ok {
+		raw = append([]byte{}, buf...)
+	}
+
+	typeName := col.DatabaseTypeName
+
+	switch {
+	case strings.Contains(typeName, "DECIMAL"), strings.Contains(typeName, "NUMERIC"):
+		s1 := toColumnString(raw)
+
+		if opts.decimalMode == DecimalAsFloat64 {
+			if n1, err := strconv.ParseFloat(s1, 64); err == nil {
+				return n1
+			}
+		}
+
+		return s1
+	case typeName == "BIT":
+		buf, ok := raw.([]byte)
+
+		if !ok {
+			return raw
+		}
+
+		switch opts.bitMode {
+		case BitAsBytes:
+			return buf
+		case BitAsBool:
+			return bitsToUint64(buf) != 0
+		}
+
+		return bitsToUint64(buf)
+	case isBinaryColumnType(typeName):
+		if opts.binaryMode == BinaryAsString {
+			return toColumnString(raw)
+		}
+
+		if s1, ok := raw.(string); ok {
+			return []byte(s1)
+		}
+
+		return raw
+	case isBoolColumn(col, opts):
+		if b1, ok := raw.(bool); ok {
+			return b1
+		}
+
+		return isTruthy(toColumnString(raw))
+	case strings.HasPrefix(typeName, "UNSIGNED"), isUnsignedScanType(col.ScanType):
+		s1 := toColumnString(raw)
+
+		if opts.unsignedMode == UnsignedAsString {
+			return s1
+		}
+
+		if n1, err := strconv.ParseUint(s1, 10, 64); err == nil {
+			return n1
+		}
+
+		return s1
+	case strings.Contains(typeName, "INT"), strings.Contains(typeName, "SERIAL"):
+		if n1, ok := raw.(int64); ok {
+			return n1
+		}
+
+		if n1, err := strconv.ParseInt(toColumnString(raw), 10, 64); err == nil {
+			return n1
+		}
+
+		return raw
+	case strings.Contains(typeName, "FLOAT"), strings.Contains(typeName, "DOUBLE"), typeName == "REAL":
+		if n1, ok := raw.(float64); ok {
+			return n1
+		}
+
+		if n1, err := strconv.ParseFloat(toColumnString(raw), 64); err == nil {
+			return n1
+		}
+
+		return raw
+	}
+
+	if buf, ok := raw.([]byte); ok {
+		return string(buf)
+	}
+
+	return raw
+}
+
+func isBoolColumn(col ColumnInfo, opts *resultSetOptions) bool {
+	switch col.DatabaseTypeName {
+	case "BOOL", "BOOLEAN", "TINYINT(1)":
+		return true
+	case "TINYINT", "UNSIGNED TINYINT":
+		return opts.tinyIntMode == TinyIntAsBool || col.Length == 1
+	}
+
+	return false
+}
+
+func isBinaryColumnType(typeName string) bool {
+	switch typeName {
+	case "BINARY", "VARBINARY", "BYTEA", "BLOB", "TINYBLOB", "MEDIUMBLOB", "LONGBLOB":
+		return true
+	}
+
+	return false
+}
+
+func isUnsignedScanType(rt reflect.Type) bool {
+	if rt == nil {
+		return false
+	}
+
+	if rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+	}
+
+	switch rt.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+
+	return false
+}
+
+func bitsToUint64(buf []byte) uint64 {
+	if len(buf) > 8 {
+		buf = buf[len(buf)-8:]
+	}
+
+	padded := make([]byte, 8)
+	copy(padded[8-len(buf):], buf)
+	return binary.BigEndian.Uint64(padded)
+}
+
+func toColumnString(value interface{}) string {
+	switch v := value.(type) {
+	case []byte:
+		return string(v)
+	case string:
+		return v
+	case time.Time:
+		return v.Format(dateFormatFull)
+	}
+
+	return fmt.Sprintf("%v", value)
+}
+
+func (rs *ResultSet) Len() int {
+	return len(rs.Rows)
+}
+
+func (rs *ResultSet) Column(name string) (ColumnInfo, bool) {
+	idx, ok := rs.index[name]
+
+	if !ok {
+		return ColumnInfo{}, false
+	}
+
+	return rs.Columns[idx], true
+}
+
+func (r *Row) Columns() []ColumnInfo {
+	return r.columns
+}
+
+func (r *Row) Get(columnName string) interface{} {
+	idx, ok := r.index[columnName]
+
+	if !ok {
+		return nil
+	}
+
+	return r.values[idx]
+}
+
+func (r *Row) Has(columnName string) bool {
+	_, ok := r.index[columnName]
+	return ok
+}
+
+func (r *Row) IsNull(columnName string) bool {
+	return r.Get(columnName) == nil
+}
+
+func (r *Row) String(columnName string, defaultValue ...string) string {
+	value := r.Get(columnName)
+
+	if value == nil {
+		if len(defaultValue) > 0 {
+			return defaultValue[0]
+		}
+
+		return ""
+	}
+
+	return toColumnString(value)
+}
+
+func (r *Row) Int64(columnName string, defaultValue ...int64) int64 {
+	var _defaultValue int64
+
+	if len(defaultValue) > 0 {
+		_defaultValue = defaultValue[0]
+	}
+
+	switch v := r.Get(columnName).(type) {
+	case nil:
+		return _defaultValue
+	case int64:
+		return v
+	case uint64:
+		return int64(v)
+	case float64:
+		return int64(v)
+	case bool:
+		if v {
+			return 1
+		}
+
+		return 0
+	}
+
+	s1 := r.String(columnName)
+
+	if n1, err := strconv.ParseInt(s1, 10, 64); err == nil {
+		return n1
+	}
+
+	if n1, err := strconv.ParseFloat(s1, 64); err == nil {
+		return int64(n1)
+	}
+
+	return _defaultValue
+}
+
+func (r *Row) Uint64(columnName string, defaultValue ...uint64) uint64 {
+	var _defaultValue uint64
+
+	if len(defaultValue) > 0 {
+		_defaultValue = defaultValue[0]
+	}
+
+	switch v := r.Get(columnName).(type) {
+	case nil:
+		return _defaultValue
+	case uint64:
+		return v
+	case int64:
+		return uint64(v)
+	}
+
+	if n1, err := strconv.ParseUint(r.String(columnName), 10, 64); err == nil {
+		return n1
+	}
+
+	return _defaultValue
+}
+
+func (r *Row) Float64(columnName string, defaultValue ...float64) float64 {
+	var _defaultValue float64
+
+	if len(defaultValue) > 0 {
+		_defaultValue = defaultValue[0]
+	}
+
+	switch v := r.Get(columnName).(type) {
+	case nil:
+		return _defaultValue
+	case float64:
+		return v
+	case int64:
+		return float64(v)
+	case uint64:
+		return float64(v)
+	}
+
+	if n1, err := strconv.ParseFloat(r.String(columnName), 64); err == nil {
+		return n1
+	}
+
+	return _defaultValue
+}
+
+func (r *Row) Bool(columnName string) bool {
+	switch v := r.Get(columnName).(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case int64:
+		return v != 0
+	case uint64:
+		return v != 0
+	}
+
+	return isTruthy(r.String(columnName))
+}
+
+func (r *Row) Decimal(columnName string) (*big.Rat, bool) {
+	value := r.Get(columnName)
+
+	if value == nil {
+		return nil, false
+	}
+
+	if n1, ok := value.(float64); ok {
+		return new(big.Rat).SetFloat64(n1), true
+	}
+
+	return new(big.Rat).SetString(toColumnString(value))
+}
+
+func (r *Row) Time(columnName string, loc ...*time.Location) time.Time {
+	value := r.Get(columnName)
+
+	if t1, ok := value.(time.Time); ok {
+		return t1
+	}
+
+	if value == nil {
+		return time.Time{}
+	}
+
+	_loc := time.Local
+
+	if len(loc) > 0 && loc[0] != nil {
+		_loc = loc[0]
+	}
+
+	s1 := toColumnString(value)
+
+	for _, layout := range []string{dateFormatFull, dateFormatDateOnly, time.RFC3339Nano} {
+		if t1, err := time.ParseInLocation(layout, s1, _loc); err == nil {
+			return t1
+		}
+	}
+
+	return time.Time{}
+}
+
+func (r *Row) Bytes(columnName string) []byte {
+	switch v := r.Get(columnName).(type) {
+	case nil:
+		return nil
+	case []byte:
+		return v
+	}
+
+	return []byte(r.String(columnName))
+}
+
+func (r *Row) ToMap() map[string]interface{} {
+	map1 := make(map[string]interface{}, len(r.columns))
+
+	for idx, col := range r.columns {
+		map1[col.Name] = r.values[idx]
+	}
+
+	return map1
+}
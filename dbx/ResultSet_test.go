@@ -0,0 +1,102 @@
+package dbx
+
+import (
+	"testing"
+	"time"
+)
+
+func TestResultSetAccessors(t *testing.T) {
+	openSqliteTestDb(t,
+		"CREATE TABLE items (id INTEGER PRIMARY KEY AUTOINCREMENT, name VARCHAR(32), qty INT, price DECIMAL(10,2), "+
+			"ratio REAL, active TINYINT(1), flag BOOLEAN, level TINYINT, ctime DATETIME, data BLOB)",
+		"INSERT INTO items (name, qty, price, ratio, active, flag, level, ctime, data) "+
+			"VALUES ('pen', 3, '1.50', 0.25, 1, 0, 2, '2024-03-01 10:00:00', x'0102')",
+		"INSERT INTO items (name) VALUES (NULL)",
+	)
+
+	rs, err := QueryResultSet("SELECT * FROM items ORDER BY id")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if rs.Len() != 2 {
+		t.Fatalf("Len() = %d, want 2", rs.Len())
+	}
+
+	if col, ok := rs.Column("price"); !ok || col.DatabaseTypeName != "DECIMAL(10,2)" {
+		t.Fatalf("Column(price) = %+v, %v", col, ok)
+	}
+
+	if _, ok := rs.Column("missing"); ok {
+		t.Fatal("Column(missing) found")
+	}
+
+	row := rs.Rows[0]
+
+	if row.String("name") != "pen" || row.Int64("qty") != 3 || row.Uint64("qty") != 3 || row.Float64("ratio") != 0.25 {
+		t.Fatalf("unexpected row %v", row.ToMap())
+	}
+
+	if v, ok := row.Get("active").(bool); !ok || !v {
+		t.Fatalf("TINYINT(1) = %#v, want true", row.Get("active"))
+	}
+
+	if v, ok := row.Get("flag").(bool); !ok || v {
+		t.Fatalf("BOOLEAN = %#v, want false", row.Get("flag"))
+	}
+
+	if v, ok := row.Get("level").(int64); !ok || v != 2 {
+		t.Fatalf("TINYINT = %#v, want int64 2", row.Get("level"))
+	}
+
+	if d, ok := row.Decimal("price"); !ok || d.FloatString(2) != "1.50" {
+		t.Fatalf("Decimal(price) = %v, %v", d, ok)
+	}
+
+	want := time.Date(2024, time.March, 1, 10, 0, 0, 0, time.UTC)
+
+	if got := row.Time("ctime", time.UTC); !got.Equal(want) {
+		t.Fatalf("Time(ctime) = %v, want %v", got, want)
+	}
+
+	if buf := row.Bytes("data"); len(buf) != 2 || buf[0] != 1 || buf[1] != 2 {
+		t.Fatalf("Bytes(data) = %v", buf)
+	}
+
+	null := rs.Rows[1]
+
+	for _, name := range []string{"name", "qty", "price", "active", "ctime", "data"} {
+		if !null.Has(name) || !null.IsNull(name) {
+			t.Errorf("%s: want NULL", name)
+		}
+	}
+
+	if null.String("name", "none") != "none" || null.Int64("qty", -1) != -1 || null.Float64("ratio", 1.5) != 1.5 || null.Bool("active") {
+		t.Fatal("NULL did not fall back to the defaults")
+	}
+
+	if _, ok := null.Decimal("price"); ok || !null.Time("ctime").IsZero() || null.Bytes("data") != nil {
+		t.Fatal("NULL returned a value")
+	}
+
+	if null.Has("missing") || null.Get("missing") != nil {
+		t.Fatal("missing column found")
+	}
+}
+
+func TestResultSetTinyIntMode(t *testing.T) {
+	opts := NewResultSetOptions().WithTinyIntMode(TinyIntAsBool)
+
+	if v := convertColumnValue(ColumnInfo{DatabaseTypeName: "TINYINT"}, int64(1), opts); v != true {
+		t.Fatalf("TINYINT with TinyIntAsBool = %#v, want true", v)
+	}
+
+	if v := convertColumnValue(ColumnInfo{DatabaseTypeName: "TINYINT"}, int64(1), NewResultSetOptions()); v != int64(1) {
+		t.Fatalf("TINYINT = %#v, want int64 1", v)
+	}
+
+	if v := convertColumnValue(ColumnInfo{DatabaseTypeName: "TINYINT", Length: 1}, []byte("0"), NewResultSetOptions()); v != false {
+		t.Fatalf("TINYINT of length 1 = %#v, want false", v)
+	}
+}
@@ -0,0 +1,124 @@
+package dbx
+
+import (
+	"database/sql"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+type scanPlanKey struct {
+	rt      reflect.Type
+	columns string
+}
+
+type scanPlanField struct {
+	field     modelField
+	columnIdx int
+}
+
+type scanPlan struct {
+	fields []scanPlanField
+}
+
+type modelScanner struct {
+	rt        reflect.Type
+	plan      *scanPlan
+	typeNames []string
+}
+
+// maxScanPlans bounds scanPlansCache, which gets one entry per model type and column list, so ad-hoc
+// column lists can't grow it without limit. The cache is dropped as a whole once the bound is hit.
+const maxScanPlans = 1024
+
+var scanPlansCache = &sync.Map{}
+var scanPlansCount int64
+
+func getScanPlan(rt reflect.Type, columnNames []string) *scanPlan {
+	key := scanPlanKey{rt: rt, columns: strings.Join(columnNames, "\x00")}
+
+	if cached, ok := scanPlansCache.Load(key); ok {
+		return cached.(*scanPlan)
+	}
+
+	plan := &scanPlan{fields: make([]scanPlanField, 0)}
+
+	for _, mf := range getModelFields(rt) {
+		idx := getColumnIdxByModelField(mf, columnNames)
+
+		if idx < 0 {
+			continue
+		}
+
+		plan.fields = append(plan.fields, scanPlanField{field: mf, columnIdx: idx})
+	}
+
+	cached, loaded := scanPlansCache.LoadOrStore(key, plan)
+
+	if !loaded && atomic.AddInt64(&scanPlansCount, 1) > maxScanPlans {
+		clearScanPlans()
+	}
+
+	return cached.(*scanPlan)
+}
+
+func clearScanPlans() {
+	scanPlansCache.Range(func(key, _ interface{}) bool {
+		scanPlansCache.Delete(key)
+		atomic.AddInt64(&scanPlansCount, -1)
+		return true
+	})
+}
+
+func newModelScanner(rs *sql.Rows, rt reflect.Type) (*modelScanner, error) {
+	if rt == nil || rt.Kind() != reflect.Ptr || rt.Elem().Kind() != reflect.Struct {
+		return nil, NewDbException("model is not struct pointer")
+	}
+
+	columnNames, err := rs.Columns()
+
+	if err != nil {
+		return nil, err
+	}
+
+	typeNames := getScanTypeNames(rs)
+
+	if len(typeNames) < 1 || len(typeNames) != len(columnNames) {
+		return nil, NewDbException("scan error")
+	}
+
+	return &modelScanner{
+		rt:        rt,
+		plan:      getScanPlan(rt.Elem(), columnNames),
+		typeNames: typeNames,
+	}, nil
+}
+
+func (s *modelScanner) scan(rs *sql.Rows, model interface{}) error {
+	if model == nil || reflect.TypeOf(model) != s.rt {
+		return NewDbException("model is not struct pointer")
+	}
+
+	scanFields, scanArgs := newScanFields(s.typeNames)
+
+	if err := rs.Scan(scanArgs...); err != nil {
+		return err
+	}
+
+	rv := reflect.ValueOf(model).Elem()
+
+	for _, item := range s.plan.fields {
+		fv, ok := resolveModelField(rv, item.field, true)
+
+		if !ok || !fv.CanSet() {
+			continue
+		}
+
+		if err := setScanFieldToModelField(fv, item.field, scanFields[item.columnIdx]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
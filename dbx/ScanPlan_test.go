@@ -0,0 +1,142 @@
+package dbx
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type scanBenchRow struct {
+	Id     int64   `db:"id"`
+	Name   string  `db:"name"`
+	Score  float64 `db:"score"`
+	Active bool    `db:"active"`
+}
+
+type scanMoney struct {
+	Cents int64
+}
+
+type scanOrder struct {
+	Id    int64     `db:"id"`
+	Price scanMoney `db:"price,prefix=price_"`
+}
+
+func TestRegisterTypeConverterInvalidatesModelFields(t *testing.T) {
+	rt := reflect.TypeOf(scanOrder{})
+	names := func() []string {
+		list := make([]string, 0)
+
+		for _, mf := range getModelFields(rt) {
+			list = append(list, mf.name)
+		}
+
+		return list
+	}
+
+	if got := strings.Join(names(), ","); got != "Id,Price.Cents" {
+		t.Fatalf("fields = %s, want Id,Price.Cents", got)
+	}
+
+	RegisterTypeConverter(scanMoney{}, NewTypeConverter(
+		func(value interface{}) (interface{}, error) { return scanMoney{}, nil },
+		func(value interface{}) (interface{}, error) { return 0, nil },
+	))
+
+	defer RemoveTypeConverter(scanMoney{})
+
+	if got := strings.Join(names(), ","); got != "Id,Price" {
+		t.Fatalf("fields after RegisterTypeConverter = %s, want Id,Price", got)
+	}
+}
+
+func TestScanPlansCacheIsBounded(t *testing.T) {
+	rt := reflect.TypeOf(scanBenchRow{})
+
+	for i := 0; i < maxScanPlans*2; i++ {
+		getScanPlan(rt, []string{"id", fmt.Sprintf("c%d", i)})
+	}
+
+	n := 0
+
+	scanPlansCache.Range(func(_, _ interface{}) bool {
+		n++
+		return true
+	})
+
+	if n > maxScanPlans {
+		t.Fatalf("scanPlansCache holds %d plans, want at most %d", n, maxScanPlans)
+	}
+}
+
+func openScanBenchDb(b *testing.B) *sql.DB {
+	db := openSqliteTestDb(b, "CREATE TABLE people (id INTEGER PRIMARY KEY, name TEXT, score REAL, active INTEGER)")
+	tx, _ := db.Begin()
+
+	for i := 1; i <= 1000; i++ {
+		if _, err := tx.Exec("INSERT INTO people VALUES (?, ?, ?, ?)", i, fmt.Sprintf("n%d", i), float64(i)/3, i%2); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		b.Fatal(err)
+	}
+
+	return db
+}
+
+func benchmarkScan(b *testing.B, scanFn func(rs *sql.Rows, rt reflect.Type) error) {
+	db := openScanBenchDb(b)
+	rt := reflect.TypeOf(&scanBenchRow{})
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		rs, err := db.Query("SELECT id, name, score, active FROM people")
+
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		if err := scanFn(rs, rt); err != nil {
+			b.Fatal(err)
+		}
+
+		_ = rs.Close()
+	}
+}
+
+// BenchmarkScanPerRow resolves fields for every row, as scanning did before scan plans.
+func BenchmarkScanPerRow(b *testing.B) {
+	benchmarkScan(b, func(rs *sql.Rows, rt reflect.Type) error {
+		for rs.Next() {
+			clearModelFieldsCache()
+
+			if err := scanIntoModel(rs, reflect.New(rt.Elem()).Interface()); err != nil {
+				return err
+			}
+		}
+
+		return rs.Err()
+	})
+}
+
+func BenchmarkScanWithPlan(b *testing.B) {
+	benchmarkScan(b, func(rs *sql.Rows, rt reflect.Type) error {
+		scanner, err := newModelScanner(rs, rt)
+
+		if err != nil {
+			return err
+		}
+
+		for rs.Next() {
+			if err := scanner.scan(rs, reflect.New(rt.Elem()).Interface()); err != nil {
+				return err
+			}
+		}
+
+		return rs.Err()
+	})
+}
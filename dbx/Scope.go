@@ -0,0 +1,276 @@
+package dbx
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+type globalScope struct {
+	name       string
+	fn         func(ctx context.Context, tableName string) (map[string]interface{}, error)
+	failClosed bool
+}
+
+var globalScopes = make([]*globalScope, 0)
+var globalScopesLock = &sync.RWMutex{}
+
+func AddGlobalScope(
+	name string,
+	fn func(ctx context.Context, tableName string) (map[string]interface{}, error),
+	failClosed ...bool,
+) {
+	if name == "" || fn == nil {
+		return
+	}
+
+	scope := &globalScope{name: name, fn: fn}
+
+	if len(failClosed) > 0 {
+		scope.failClosed = failClosed[0]
+	}
+
+	globalScopesLock.Lock()
+	defer globalScopesLock.Unlock()
+
+	for idx, item := range globalScopes {
+		if item.name == name {
+			globalScopes[idx] = scope
+			return
+		}
+	}
+
+	globalScopes = append(globalScopes, scope)
+}
+
+func RemoveGlobalScope(name string) {
+	globalScopesLock.Lock()
+	defer globalScopesLock.Unlock()
+	list := make([]*globalScope, 0, len(globalScopes))
+
+	for _, item := range globalScopes {
+		if item.name != name {
+			list = append(list, item)
+		}
+	}
+
+	globalScopes = list
+}
+
+// NewContextValueScope scopes the given tables, or else every table whose cached schema has the column,
+// to the context value under ctxKey. Without table names it fails on tables with no cached schema, so a
+// fail-closed scope never lets a query through unscoped before BuildTableSchemas has run.
+func NewContextValueScope(
+	columnName string,
+	ctxKey interface{},
+	tableNames ...string,
+) func(ctx context.Context, tableName string) (map[string]interface{}, error) {
+	return func(ctx context.Context, tableName string) (map[string]interface{}, error) {
+		if len(tableNames) > 0 {
+			if !inStringSlice(tableName, tableNames) {
+				return nil, nil
+			}
+		} else if schema := getTableSchema(tableName); schema == nil {
+			return nil, NewDbException(fmt.Sprintf("schema of table [%s] unknown, build table schemas or pass table names", tableName))
+		} else if _, ok := schema.GetField(columnName); !ok {
+			return nil, nil
+		}
+
+		var value interface{}
+
+		if ctx != nil {
+			value = ctx.Value(ctxKey)
+		}
+
+		if value == nil {
+			return nil, NewDbException(fmt.Sprintf("scope value [%s] missing in context", columnName))
+		}
+
+		return map[string]interface{}{columnName: value}, nil
+	}
+}
+
+func getGlobalScopes() []*globalScope {
+	globalScopesLock.RLock()
+	defer globalScopesLock.RUnlock()
+	list := make([]*globalScope, len(globalScopes))
+	copy(list, globalScopes)
+	return list
+}
+
+func (qb *queryBuilder) resolveScopes() error {
+	if qb.err != nil {
+		return qb.err
+	}
+
+	qb.scopeValues = map[string]map[string]interface{}{}
+
+	if err := qb.resolveCteScopes(); err != nil {
+		return err
+	}
+
+	scopes := getGlobalScopes()
+
+	if len(scopes) < 1 || len(qb.tables) < 1 {
+		return nil
+	}
+
+	tables := []table{qb.tables[0]}
+
+	for _, item := range qb.joinClauses {
+		tables = append(tables, item.tbl)
+	}
+
+	for _, tbl := range tables {
+		tableName := normalizeTableName(tbl.name)
+
+		if qb.isCteName(tbl.name) {
+			continue
+		}
+
+		if _, ok := qb.scopeValues[tableName]; ok {
+			continue
+		}
+
+		values := map[string]interface{}{}
+
+		for _, scope := range scopes {
+			if inStringSlice(scope.name, qb.withoutScopes) {
+				continue
+			}
+
+			map1, err := scope.fn(qb.getContext(), tableName)
+
+			if err != nil {
+				if scope.failClosed {
+					err = NewDbException(fmt.Sprintf("global scope [%s] rejected query: %s", scope.name, err.Error()))
+					writeLog("error", err)
+					return err
+				}
+
+				continue
+			}
+
+			for columnName, value := range map1 {
+				values[columnName] = value
+			}
+		}
+
+		qb.scopeValues[tableName] = values
+	}
+
+	return nil
+}
+
+func (qb *queryBuilder) buildGlobalScopeConditions(tbl table, qualified bool) (conditions []string, params []interface{}) {
+	values := qb.scopeValues[normalizeTableName(tbl.name)]
+
+	if len(values) < 1 {
+		return
+	}
+
+	for _, columnName := range sortedMapKeys(values) {
+		qualifiedName := columnName
+
+		if qualified {
+			if tbl.alias != "" {
+				qualifiedName = tbl.alias + "." + columnName
+			} else {
+				qualifiedName = normalizeTableName(tbl.name) + "." + columnName
+			}
+		}
+
+		qualifiedName = quote(qualifiedName)
+		value := indirect(values[columnName])
+
+		if value == nil {
+			conditions = append(conditions, qualifiedName+" IS NULL")
+			continue
+		}
+
+		if v, ok := value.(rawSql); ok {
+			conditions = append(conditions, qualifiedName+" = "+v.expr)
+			params = append(params, v.bindings...)
+			continue
+		}
+
+		if isListValue(value) {
+			list := toSlice(value)
+
+			if len(list) < 1 {
+				conditions = append(conditions, "1 = 0")
+				continue
+			}
+
+			marks := strings.TrimSuffix(strings.Repeat("?, ", len(list)), ", ")
+			conditions = append(conditions, fmt.Sprintf("%s IN (%s)", qualifiedName, marks))
+			params = append(params, list...)
+			continue
+		}
+
+		conditions = append(conditions, qualifiedName+" = ?")
+		params = append(params, value)
+	}
+
+	return
+}
+
+// injectGlobalScopeValues fills in the scope columns of the target table that the caller left out,
+// and rejects caller-supplied values that would write a row outside the current scope.
+func (qb *queryBuilder) injectGlobalScopeValues(data map[string]interface{}) error {
+	if len(qb.tables) < 1 {
+		return nil
+	}
+
+	values := qb.scopeValues[normalizeTableName(qb.tables[0].name)]
+
+	for _, columnName := range sortedMapKeys(values) {
+		value := indirect(values[columnName])
+		supplied, ok := data[columnName]
+
+		if !ok {
+			if !isListValue(value) {
+				data[columnName] = values[columnName]
+			}
+
+			continue
+		}
+
+		if !scopeValueAllows(value, indirect(supplied)) {
+			return NewDbException(fmt.Sprintf("value of column [%s] is out of global scope", columnName))
+		}
+	}
+
+	return nil
+}
+
+func scopeValueAllows(scopeValue, value interface{}) bool {
+	if scopeValue == nil || value == nil {
+		return scopeValue == nil && value == nil
+	}
+
+	if _, ok := scopeValue.(rawSql); ok {
+		return false
+	}
+
+	_, key, ok := toRelationKey(value)
+
+	if !ok {
+		return false
+	}
+
+	candidates := []interface{}{scopeValue}
+
+	if isListValue(scopeValue) {
+		candidates = toSlice(scopeValue)
+	}
+
+	for _, item := range candidates {
+		if _, s1, ok := toRelationKey(item); ok && s1 == key {
+			return true
+		}
+	}
+
+	return false
+}
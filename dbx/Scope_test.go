@@ -0,0 +1,131 @@
+package dbx
+
+import (
+	"context"
+	"testing"
+)
+
+func TestGlobalScopeRawBindings(t *testing.T) {
+	AddGlobalScope("test_raw", func(ctx context.Context, tableName string) (map[string]interface{}, error) {
+		return map[string]interface{}{"tenant_id": Raw("COALESCE(?, ?)", 7, 8)}, nil
+	})
+
+	defer RemoveGlobalScope("test_raw")
+	qb := Table("orders").Where("status", 1)
+
+	if err := qb.resolveScopes(); err != nil {
+		t.Fatal(err)
+	}
+
+	query, params := qb.buildSelectSql()
+	want := "SELECT * FROM `orders` WHERE `status` = ? AND `tenant_id` = COALESCE(?, ?)"
+
+	if query != want {
+		t.Fatalf("sql = %q, want %q", query, want)
+	}
+
+	if len(params) != 3 || params[0] != 1 || params[1] != 7 || params[2] != 8 {
+		t.Fatalf("params = %v, want [1 7 8]", params)
+	}
+}
+
+func TestInjectGlobalScopeValues(t *testing.T) {
+	AddGlobalScope("test_tenant", func(ctx context.Context, tableName string) (map[string]interface{}, error) {
+		return map[string]interface{}{"tenant_id": int64(7)}, nil
+	})
+
+	defer RemoveGlobalScope("test_tenant")
+	qb := Table("orders")
+
+	if err := qb.resolveScopes(); err != nil {
+		t.Fatal(err)
+	}
+
+	data := map[string]interface{}{"status": 1}
+
+	if err := qb.injectGlobalScopeValues(data); err != nil {
+		t.Fatal(err)
+	}
+
+	if data["tenant_id"] != int64(7) {
+		t.Fatalf("tenant_id = %v, want 7", data["tenant_id"])
+	}
+
+	if err := qb.injectGlobalScopeValues(map[string]interface{}{"tenant_id": 7}); err != nil {
+		t.Fatalf("matching tenant_id rejected: %v", err)
+	}
+
+	if err := qb.injectGlobalScopeValues(map[string]interface{}{"tenant_id": 8}); err == nil {
+		t.Fatal("foreign tenant_id accepted")
+	}
+}
+
+func TestInsertUsingAppliesTargetScope(t *testing.T) {
+	AddGlobalScope("test_tenant", func(ctx context.Context, tableName string) (map[string]interface{}, error) {
+		if tableName == "archive" {
+			return map[string]interface{}{"tenant_id": int64(9)}, nil
+		}
+
+		return map[string]interface{}{"tenant_id": int64(7)}, nil
+	})
+
+	defer RemoveGlobalScope("test_tenant")
+	qb := Table("archive")
+	subQb := Table("orders").Select("id, status")
+
+	if err := qb.resolveScopes(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := subQb.resolveScopes(); err != nil {
+		t.Fatal(err)
+	}
+
+	query, params, err := qb.buildInsertUsingSql("id, status", subQb)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "INSERT INTO `archive` (`id`, `status`, `tenant_id`) SELECT `dbx_src`.*, ? FROM " +
+		"(SELECT `id`, `status` FROM `orders` WHERE `tenant_id` = ?) AS `dbx_src`"
+
+	if query != want {
+		t.Fatalf("sql = %q, want %q", query, want)
+	}
+
+	if len(params) != 2 || params[0] != int64(9) || params[1] != int64(7) {
+		t.Fatalf("params = %v, want [9 7]", params)
+	}
+
+	if _, _, err := qb.buildInsertUsingSql("id, tenant_id", subQb); err == nil {
+		t.Fatal("explicit scope column accepted")
+	}
+}
+
+type testTenantKey struct{}
+
+func TestContextValueScopeWithoutSchema(t *testing.T) {
+	tableSchemasLock.Lock()
+	tableSchemas = map[string]*TableSchema{}
+	tableSchemasLock.Unlock()
+
+	AddGlobalScope("test_ctx_tenant", NewContextValueScope("tenant_id", testTenantKey{}), true)
+	defer RemoveGlobalScope("test_ctx_tenant")
+	ctx := context.WithValue(context.Background(), testTenantKey{}, 7)
+
+	if err := Table("orders").WithContext(ctx).resolveScopes(); err == nil {
+		t.Fatal("query ran unscoped with an empty schema cache")
+	}
+
+	AddGlobalScope("test_ctx_tenant", NewContextValueScope("tenant_id", testTenantKey{}, "orders"), true)
+	qb := Table("orders").WithContext(ctx)
+
+	if err := qb.resolveScopes(); err != nil {
+		t.Fatal(err)
+	}
+
+	if query, _ := qb.buildSelectSql(); query != "SELECT * FROM `orders` WHERE `tenant_id` = ?" {
+		t.Fatalf("sql = %q", query)
+	}
+}
@@ -0,0 +1,26 @@
+package dbx
+
+import (
+	"fmt"
+)
+
+type StaleModelException struct {
+	tableName string
+	version   int64
+}
+
+func NewStaleModelException(tableName string, version int64) StaleModelException {
+	return StaleModelException{tableName: tableName, version: version}
+}
+
+func (ex StaleModelException) Error() string {
+	return fmt.Sprintf("stale model: row in table [%s] was modified since version %d", ex.tableName, ex.version)
+}
+
+func (ex StaleModelException) TableName() string {
+	return ex.tableName
+}
+
+func (ex StaleModelException) Version() int64 {
+	return ex.version
+}
@@ -0,0 +1,97 @@
+package dbx
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+type versionedArticle struct {
+	Id      int64  `db:"id,pk"`
+	Title   string `db:"title"`
+	Version int64  `db:"version"`
+}
+
+func TestUpdateByModelIncrementsVersion(t *testing.T) {
+	db := openSqliteTestDb(t,
+		"CREATE TABLE articles (id INTEGER PRIMARY KEY AUTOINCREMENT, title TEXT, version INTEGER NOT NULL DEFAULT 1)",
+		"INSERT INTO articles (title, version) VALUES ('a', 1)",
+	)
+
+	var a versionedArticle
+
+	if err := Table("articles").Where("id", 1).FirstInto(&a); err != nil {
+		t.Fatal(err)
+	}
+
+	a.Title = "b"
+
+	if n, err := Table("articles").UpdateByModel(&a); err != nil || n != 1 {
+		t.Fatalf("UpdateByModel() = %d, %v, want 1", n, err)
+	}
+
+	if a.Version != 2 {
+		t.Fatalf("model version = %d, want 2", a.Version)
+	}
+
+	if n := countRows(t, db, "SELECT COUNT(*) FROM articles WHERE title = 'b' AND version = 2"); n != 1 {
+		t.Fatal("row version was not incremented")
+	}
+}
+
+func TestConcurrentStaleUpdates(t *testing.T) {
+	db := openSqliteTestDb(t,
+		"CREATE TABLE articles (id INTEGER PRIMARY KEY AUTOINCREMENT, title TEXT, version INTEGER NOT NULL DEFAULT 1)",
+		"INSERT INTO articles (title, version) VALUES ('a', 1)",
+	)
+
+	db.SetMaxOpenConns(1)
+	copies := make([]*versionedArticle, 2)
+
+	for idx := range copies {
+		copies[idx] = &versionedArticle{}
+
+		if err := Table("articles").Where("id", 1).FirstInto(copies[idx]); err != nil {
+			t.Fatal(err)
+		}
+
+		copies[idx].Title = []string{"x", "y"}[idx]
+	}
+
+	errs := make([]error, len(copies))
+	wg := sync.WaitGroup{}
+
+	for idx := range copies {
+		wg.Add(1)
+
+		go func(idx int) {
+			defer wg.Done()
+			_, errs[idx] = Table("articles").UpdateByModel(copies[idx])
+		}(idx)
+	}
+
+	wg.Wait()
+	var stale StaleModelException
+	var succeeded int
+
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			succeeded++
+		case errors.As(err, &stale):
+			if stale.TableName() != "articles" || stale.Version() != 1 {
+				t.Fatalf("unexpected stale exception %v", stale)
+			}
+		default:
+			t.Fatal(err)
+		}
+	}
+
+	if succeeded != 1 || stale.Version() != 1 {
+		t.Fatalf("errors = %v, want exactly one StaleModelException", errs)
+	}
+
+	if n := countRows(t, db, "SELECT COUNT(*) FROM articles WHERE version = 2"); n != 1 {
+		t.Fatal("row version was not incremented exactly once")
+	}
+}
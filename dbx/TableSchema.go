@@ -0,0 +1,162 @@
+package dbx
+
+import (
+	"strings"
+)
+
+type TableFieldInfo struct {
+	FieldName     string
+	FieldType     string
+	FieldSize     int
+	Unsigned      bool
+	Nullable      bool
+	DefaultValue  string
+	AutoIncrement bool
+	IsPrimaryKey  bool
+	Comment       string
+}
+
+type TableIndexInfo struct {
+	IndexName  string
+	FieldNames []string
+	Unique     bool
+	IsPrimary  bool
+}
+
+func (idx TableIndexInfo) IsComposite() bool {
+	return len(idx.FieldNames) > 1
+}
+
+type ForeignKeyInfo struct {
+	ConstraintName string
+	FieldNames     []string
+	RefTableName   string
+	RefFieldNames  []string
+	OnUpdate       string
+	OnDelete       string
+}
+
+type TableSchema struct {
+	TableName   string
+	Comment     string
+	Fields      []TableFieldInfo
+	Indexes     []TableIndexInfo
+	ForeignKeys []ForeignKeyInfo
+}
+
+// clone copies ts deeply enough that callers cannot reach the cached schema through its slices.
+func (ts *TableSchema) clone() TableSchema {
+	schema := *ts
+	schema.Fields = append([]TableFieldInfo(nil), ts.Fields...)
+	schema.Indexes = make([]TableIndexInfo, len(ts.Indexes))
+	schema.ForeignKeys = make([]ForeignKeyInfo, len(ts.ForeignKeys))
+
+	for idx, item := range ts.Indexes {
+		item.FieldNames = append([]string(nil), item.FieldNames...)
+		schema.Indexes[idx] = item
+	}
+
+	for idx, item := range ts.ForeignKeys {
+		item.FieldNames = append([]string(nil), item.FieldNames...)
+		item.RefFieldNames = append([]string(nil), item.RefFieldNames...)
+		schema.ForeignKeys[idx] = item
+	}
+
+	return schema
+}
+
+func (ts *TableSchema) GetField(fieldName string) (TableFieldInfo, bool) {
+	for _, item := range ts.Fields {
+		if item.FieldName == fieldName {
+			return item, true
+		}
+	}
+
+	return TableFieldInfo{}, false
+}
+
+func (ts *TableSchema) GetPrimaryKeys() []string {
+	list := make([]string, 0)
+
+	for _, item := range ts.Fields {
+		if item.IsPrimaryKey {
+			list = append(list, item.FieldName)
+		}
+	}
+
+	return list
+}
+
+func (ts *TableSchema) GetIndex(indexName string) (TableIndexInfo, bool) {
+	for _, item := range ts.Indexes {
+		if item.IndexName == indexName {
+			return item, true
+		}
+	}
+
+	return TableIndexInfo{}, false
+}
+
+func (ts *TableSchema) GetUniqueIndexes() []TableIndexInfo {
+	list := make([]TableIndexInfo, 0)
+
+	for _, item := range ts.Indexes {
+		if item.Unique {
+			list = append(list, item)
+		}
+	}
+
+	return list
+}
+
+func (ts *TableSchema) addIndexField(indexName, fieldName string, unique, isPrimary bool) {
+	if indexName == "" || fieldName == "" {
+		return
+	}
+
+	for idx, item := range ts.Indexes {
+		if item.IndexName == indexName {
+			ts.Indexes[idx].FieldNames = append(item.FieldNames, fieldName)
+			return
+		}
+	}
+
+	ts.Indexes = append(ts.Indexes, TableIndexInfo{
+		IndexName:  indexName,
+		FieldNames: []string{fieldName},
+		Unique:     unique || isPrimary,
+		IsPrimary:  isPrimary,
+	})
+}
+
+func (ts *TableSchema) addForeignKeyField(constraintName, fieldName, refTableName, refFieldName, onUpdate, onDelete string) {
+	if constraintName == "" || fieldName == "" {
+		return
+	}
+
+	for idx, item := range ts.ForeignKeys {
+		if item.ConstraintName == constraintName {
+			ts.ForeignKeys[idx].FieldNames = append(item.FieldNames, fieldName)
+			ts.ForeignKeys[idx].RefFieldNames = append(item.RefFieldNames, refFieldName)
+			return
+		}
+	}
+
+	ts.ForeignKeys = append(ts.ForeignKeys, ForeignKeyInfo{
+		ConstraintName: constraintName,
+		FieldNames:     []string{fieldName},
+		RefTableName:   refTableName,
+		RefFieldNames:  []string{refFieldName},
+		OnUpdate:       strings.ToUpper(onUpdate),
+		OnDelete:       strings.ToUpper(onDelete),
+	})
+}
+
+func (ts *TableSchema) markPrimaryKey(fieldName string) {
+	for idx, item := range ts.Fields {
+		if item.FieldName == fieldName {
+			ts.Fields[idx].IsPrimaryKey = true
+			return
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package dbx
+
+import (
+	"testing"
+)
+
+func TestDescribeSqliteTable(t *testing.T) {
+	openSqliteTestDb(t,
+		"CREATE TABLE users (id INTEGER PRIMARY KEY AUTOINCREMENT, email VARCHAR(64) NOT NULL)",
+		"CREATE TABLE orders (id INTEGER PRIMARY KEY AUTOINCREMENT, user_id INTEGER NOT NULL, no VARCHAR(32), "+
+			"amount DECIMAL(10,2) DEFAULT 0, FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE)",
+		"CREATE UNIQUE INDEX uk_orders_no ON orders (no)",
+		"CREATE INDEX idx_orders_user ON orders (user_id, amount)",
+	)
+
+	schema, ok := GetTableSchema("orders")
+
+	if !ok {
+		t.Fatal("schema of orders not built")
+	}
+
+	if pks := schema.GetPrimaryKeys(); len(pks) != 1 || pks[0] != "id" {
+		t.Fatalf("GetPrimaryKeys() = %v, want [id]", pks)
+	}
+
+	if field, ok := schema.GetField("no"); !ok || field.FieldType != "varchar" || field.FieldSize != 32 || !field.Nullable {
+		t.Fatalf("GetField(no) = %+v, %v", field, ok)
+	}
+
+	if field, ok := schema.GetField("user_id"); !ok || field.Nullable {
+		t.Fatalf("GetField(user_id) = %+v, %v", field, ok)
+	}
+
+	if index, ok := schema.GetIndex("idx_orders_user"); !ok || !index.IsComposite() || index.Unique {
+		t.Fatalf("GetIndex(idx_orders_user) = %+v, %v", index, ok)
+	}
+
+	if unique := schema.GetUniqueIndexes(); len(unique) != 1 || unique[0].FieldNames[0] != "no" {
+		t.Fatalf("GetUniqueIndexes() = %+v", unique)
+	}
+
+	if len(schema.ForeignKeys) != 1 {
+		t.Fatalf("foreign keys = %+v", schema.ForeignKeys)
+	}
+
+	fk := schema.ForeignKeys[0]
+
+	if fk.RefTableName != "users" || fk.FieldNames[0] != "user_id" || fk.RefFieldNames[0] != "id" || fk.OnDelete != "CASCADE" {
+		t.Fatalf("foreign key = %+v", fk)
+	}
+}
+
+func TestTableSchemasAreCopies(t *testing.T) {
+	openSqliteTestDb(t,
+		"CREATE TABLE users (id INTEGER PRIMARY KEY AUTOINCREMENT, email VARCHAR(64))",
+		"CREATE TABLE orders (id INTEGER PRIMARY KEY AUTOINCREMENT, user_id INTEGER REFERENCES users (id))",
+		"CREATE INDEX idx_orders_user ON orders (user_id)",
+	)
+
+	for _, schema := range GetTableSchemas() {
+		schema.Fields[0].FieldName = "changed"
+
+		for idx := range schema.Indexes {
+			schema.Indexes[idx].FieldNames[0] = "changed"
+		}
+
+		for idx := range schema.ForeignKeys {
+			schema.ForeignKeys[idx].RefFieldNames[0] = "changed"
+		}
+	}
+
+	schema, _ := GetTableSchema("orders")
+	schema.Fields[1].FieldName = "changed"
+
+	schema, _ = GetTableSchema("orders")
+
+	if schema.Fields[0].FieldName != "id" || schema.Fields[1].FieldName != "user_id" {
+		t.Fatalf("cached fields changed: %+v", schema.Fields)
+	}
+
+	if schema.Indexes[0].FieldNames[0] != "user_id" || schema.ForeignKeys[0].RefFieldNames[0] != "id" {
+		t.Fatalf("cached indexes or foreign keys changed: %+v, %+v", schema.Indexes, schema.ForeignKeys)
+	}
+}
+
+func TestQueryStringColumnReturnsScanErrors(t *testing.T) {
+	db := openSqliteTestDb(t, "CREATE TABLE tags (name TEXT, kind TEXT)", "INSERT INTO tags VALUES ('a', 'x'), (NULL, 'y')")
+
+	if list, err := queryStringColumn(db, "SELECT name FROM tags"); err != nil || len(list) != 1 {
+		t.Fatalf("queryStringColumn() = %v, %v, want [a]", list, err)
+	}
+
+	if _, err := queryStringColumn(db, "SELECT name, kind FROM tags"); err == nil {
+		t.Fatal("scan error swallowed")
+	}
+}
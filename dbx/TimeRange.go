@@ -0,0 +1,158 @@
+package dbx
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// WhereTimeBetween matches the half-open range [from, to). An empty or reversed range matches
+// no rows. On DATE columns the range is widened to the whole days it touches.
+func (qb *queryBuilder) WhereTimeBetween(columnName string, from, to time.Time, or ...bool) *queryBuilder {
+	if !isValidIdentifier(columnName, false) {
+		return qb.setErr(newInvalidIdentifierException("column", columnName))
+	}
+
+	if from.IsZero() || to.IsZero() {
+		return qb.setErr(NewDbException(fmt.Sprintf("time range of column [%s] has a zero bound", columnName)))
+	}
+
+	if !to.After(from) {
+		qb.addCondition("1 = 0", len(or) > 0 && or[0])
+		return qb
+	}
+
+	if qb.isDateColumn(columnName) {
+		from, to = widenToDays(from, to, qb.timeLocation(columnName))
+	}
+
+	v1, v2 := qb.timeBindValue(columnName, from), qb.timeBindValue(columnName, to)
+	condition := "(" + quote(columnName) + " >= ? AND " + quote(columnName) + " < ?)"
+	qb.addCondition(condition, len(or) > 0 && or[0])
+	qb.addBindValues(v1, v2)
+	return qb
+}
+
+func (qb *queryBuilder) OrWhereTimeBetween(columnName string, from, to time.Time) *queryBuilder {
+	return qb.WhereTimeBetween(columnName, from, to, true)
+}
+
+func (qb *queryBuilder) WhereYear(columnName string, year int, or ...bool) *queryBuilder {
+	loc := qb.timeLocation(columnName)
+	from := time.Date(year, time.January, 1, 0, 0, 0, 0, loc)
+	return qb.WhereTimeBetween(columnName, from, from.AddDate(1, 0, 0), or...)
+}
+
+func (qb *queryBuilder) OrWhereYear(columnName string, year int) *queryBuilder {
+	return qb.WhereYear(columnName, year, true)
+}
+
+func (qb *queryBuilder) WhereMonth(columnName string, year, month int, or ...bool) *queryBuilder {
+	if month < 1 || month > 12 {
+		return qb.setErr(NewDbException(fmt.Sprintf("month %d of column [%s] out of range 1-12", month, columnName)))
+	}
+
+	loc := qb.timeLocation(columnName)
+	from := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, loc)
+	return qb.WhereTimeBetween(columnName, from, from.AddDate(0, 1, 0), or...)
+}
+
+func (qb *queryBuilder) OrWhereMonth(columnName string, year, month int) *queryBuilder {
+	return qb.WhereMonth(columnName, year, month, true)
+}
+
+func (qb *queryBuilder) WhereDay(columnName string, day time.Time, or ...bool) *queryBuilder {
+	if day.IsZero() {
+		return qb.setErr(NewDbException(fmt.Sprintf("day of column [%s] is zero", columnName)))
+	}
+
+	day = day.In(qb.timeLocation(columnName))
+	from := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+	return qb.WhereTimeBetween(columnName, from, from.AddDate(0, 0, 1), or...)
+}
+
+func (qb *queryBuilder) OrWhereDay(columnName string, day time.Time) *queryBuilder {
+	return qb.WhereDay(columnName, day, true)
+}
+
+func (qb *queryBuilder) WhereToday(columnName string) *queryBuilder {
+	return qb.WhereDay(columnName, time.Now())
+}
+
+func (qb *queryBuilder) WhereLastNDays(columnName string, n int) *queryBuilder {
+	if n < 1 {
+		return qb.setErr(NewDbException(fmt.Sprintf("day count %d of column [%s] must be at least 1", n, columnName)))
+	}
+
+	now := time.Now().In(qb.timeLocation(columnName))
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()).AddDate(0, 0, 1)
+	return qb.WhereTimeBetween(columnName, to.AddDate(0, 0, -n), to)
+}
+
+func (qb *queryBuilder) resolveColumnTable(columnName string) (tableName, fieldName string) {
+	if len(qb.tables) < 1 {
+		return "", columnName
+	}
+
+	columnName = strings.NewReplacer("`", "", `"`, "").Replace(columnName)
+
+	if !strings.Contains(columnName, ".") {
+		return qb.tables[0].name, columnName
+	}
+
+	prefix := substringBefore(columnName, ".")
+	fieldName = substringAfter(columnName, ".")
+	tables := []table{qb.tables[0]}
+
+	for _, item := range qb.joinClauses {
+		tables = append(tables, item.tbl)
+	}
+
+	for _, tbl := range tables {
+		if tbl.alias == prefix || normalizeTableName(tbl.name) == prefix {
+			return tbl.name, fieldName
+		}
+	}
+
+	return qb.tables[0].name, fieldName
+}
+
+func (qb *queryBuilder) timeLocation(columnName string) *time.Location {
+	tableName, _ := qb.resolveColumnTable(columnName)
+	return getConventions(tableName).loc
+}
+
+func (qb *queryBuilder) isDateColumn(columnName string) bool {
+	tableName, fieldName := qb.resolveColumnTable(columnName)
+
+	if field, ok := findTableField(tableName, fieldName); ok {
+		return getTimeColumnKind(field) == "date"
+	}
+
+	return false
+}
+
+func widenToDays(from, to time.Time, loc *time.Location) (time.Time, time.Time) {
+	from, to = from.In(loc), to.In(loc)
+	dayFrom := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, loc)
+	dayTo := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, loc)
+
+	if dayTo.Before(to) {
+		dayTo = dayTo.AddDate(0, 0, 1)
+	}
+
+	return dayFrom, dayTo
+}
+
+func (qb *queryBuilder) timeBindValue(columnName string, t1 time.Time) interface{} {
+	tableName, fieldName := qb.resolveColumnTable(columnName)
+	c := getConventions(tableName)
+
+	if field, ok := findTableField(tableName, fieldName); ok {
+		if value, ok := c.timeValueForField(field, &t1); ok {
+			return value
+		}
+	}
+
+	return t1.In(c.loc).Format(dateFormatFull)
+}
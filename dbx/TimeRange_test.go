@@ -0,0 +1,75 @@
+package dbx
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWhereTimeBetweenInvalidBounds(t *testing.T) {
+	from := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	if err := Table("orders").WhereTimeBetween("created_at", time.Time{}, from).Err(); err == nil {
+		t.Fatal("zero bound accepted")
+	}
+
+	query, _ := Table("orders").WhereTimeBetween("created_at", from, from).buildSelectSql()
+
+	if want := "SELECT * FROM `orders` WHERE 1 = 0"; query != want {
+		t.Fatalf("sql = %q, want %q", query, want)
+	}
+}
+
+func TestWhereTimeBetweenDateColumn(t *testing.T) {
+	openSqliteTestDb(t,
+		"CREATE TABLE events (id INTEGER PRIMARY KEY AUTOINCREMENT, day DATE)",
+		"INSERT INTO events (day) VALUES ('2024-03-01'), ('2024-03-02'), ('2024-03-03')",
+	)
+
+	loc := getConventions("events").loc
+	from := time.Date(2024, time.March, 2, 9, 0, 0, 0, loc)
+	n, err := Table("events").WhereTimeBetween("day", from, from.Add(2*time.Hour)).Count()
+
+	if err != nil || n != 1 {
+		t.Fatalf("Count() = %d, %v, want 1", n, err)
+	}
+}
+
+func TestCalendarHelpersRejectInvalidInput(t *testing.T) {
+	builders := map[string]*queryBuilder{
+		"month 0":     Table("orders").WhereMonth("created_at", 2024, 0),
+		"month 13":    Table("orders").OrWhereMonth("created_at", 2024, 13),
+		"zero day":    Table("orders").WhereDay("created_at", time.Time{}),
+		"last 0 days": Table("orders").WhereLastNDays("created_at", 0),
+	}
+
+	for name, qb := range builders {
+		if qb.Err() == nil {
+			t.Errorf("%s: invalid input accepted", name)
+		}
+	}
+}
+
+func TestOrCalendarHelpers(t *testing.T) {
+	day := time.Date(2024, time.March, 2, 15, 0, 0, 0, time.UTC)
+
+	qb := Table("orders").
+		Where("status", 1).
+		OrWhereYear("created_at", 2023).
+		OrWhereMonth("created_at", 2024, 1).
+		OrWhereDay("created_at", day)
+
+	if err := qb.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	query, params := qb.buildSelectSql()
+
+	if n := strings.Count(query, " OR (`created_at` >= ? AND `created_at` < ?)"); n != 3 {
+		t.Fatalf("unexpected sql %q", query)
+	}
+
+	if len(params) != 7 {
+		t.Fatalf("got %d params, want 7", len(params))
+	}
+}
@@ -0,0 +1,192 @@
+package dbx
+
+import (
+	"reflect"
+	"time"
+)
+
+type TrackedModel struct {
+	originalValues map[string]interface{}
+}
+
+var trackedModelType = reflect.TypeOf(TrackedModel{})
+
+type trackable interface {
+	getOriginalValues() map[string]interface{}
+	setOriginalValues(values map[string]interface{})
+}
+
+func (m *TrackedModel) getOriginalValues() map[string]interface{} {
+	return m.originalValues
+}
+
+func (m *TrackedModel) setOriginalValues(values map[string]interface{}) {
+	m.originalValues = values
+}
+
+func (m *TrackedModel) IsTracked() bool {
+	return m.originalValues != nil
+}
+
+func rememberOriginalValues(model interface{}) {
+	tracker, ok := model.(trackable)
+
+	if !ok {
+		return
+	}
+
+	rv := reflect.ValueOf(model)
+
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return
+	}
+
+	tracker.setOriginalValues(snapshotModel(rv.Elem()))
+}
+
+func snapshotModel(rv reflect.Value) map[string]interface{} {
+	values := map[string]interface{}{}
+
+	for _, mf := range getModelFields(rv.Type()) {
+		fv, ok := resolveModelField(rv, mf, false)
+
+		if !ok {
+			values[mf.name] = nil
+			continue
+		}
+
+		if fv.Kind() == reflect.Ptr {
+			if fv.IsNil() {
+				values[mf.name] = nil
+			} else {
+				values[mf.name] = cloneValue(fv.Elem()).Interface()
+			}
+
+			continue
+		}
+
+		values[mf.name] = cloneValue(fv).Interface()
+	}
+
+	return values
+}
+
+// cloneValue deep copies maps, slices, arrays and pointers, so that a snapshot does not share memory
+// with the model and in-place edits such as m.Tags[0] = "x" still show up as dirty.
+func cloneValue(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+
+		c := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+
+		for iter.Next() {
+			c.SetMapIndex(iter.Key(), cloneValue(iter.Value()))
+		}
+
+		return c
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(cloneValue(v.Index(i)))
+		}
+
+		return c
+	case reflect.Array:
+		c := reflect.New(v.Type()).Elem()
+
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(cloneValue(v.Index(i)))
+		}
+
+		return c
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+
+		c := reflect.New(v.Type().Elem())
+		c.Elem().Set(cloneValue(v.Elem()))
+		return c
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+
+		c := reflect.New(v.Type()).Elem()
+		c.Set(cloneValue(v.Elem()))
+		return c
+	case reflect.Struct:
+		c := reflect.New(v.Type()).Elem()
+		c.Set(v)
+
+		for i := 0; i < v.NumField(); i++ {
+			if c.Field(i).CanSet() {
+				c.Field(i).Set(cloneValue(v.Field(i)))
+			}
+		}
+
+		return c
+	}
+
+	return v
+}
+
+func getDirtyFields(model interface{}) (fieldNames []string, tracked bool) {
+	tracker, ok := model.(trackable)
+
+	if !ok || tracker.getOriginalValues() == nil {
+		return nil, false
+	}
+
+	originalValues := tracker.getOriginalValues()
+	currentValues := snapshotModel(reflect.ValueOf(model).Elem())
+	fieldNames = make([]string, 0)
+
+	for fieldName, value := range currentValues {
+		if !isSameFieldValue(originalValues[fieldName], value) {
+			fieldNames = append(fieldNames, fieldName)
+		}
+	}
+
+	return fieldNames, true
+}
+
+func isSameFieldValue(v1, v2 interface{}) bool {
+	t1, ok1 := v1.(time.Time)
+	t2, ok2 := v2.(time.Time)
+
+	if ok1 && ok2 {
+		return t1.Equal(t2)
+	}
+
+	return reflect.DeepEqual(v1, v2)
+}
+
+func getModelPkField(tableName string, rt reflect.Type, rv reflect.Value) (string, reflect.Value) {
+	for _, mf := range getModelFields(rt) {
+		columnName := getModelFieldColumnName(tableName, mf)
+
+		if columnName == "" || !mf.isPk(tableName, columnName) {
+			continue
+		}
+
+		fv, ok := resolveModelField(rv, mf, false)
+
+		if !ok {
+			return mf.name, reflect.Value{}
+		}
+
+		return mf.name, reflect.Indirect(fv)
+	}
+
+	return "", reflect.Value{}
+}
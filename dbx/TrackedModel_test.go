@@ -0,0 +1,100 @@
+package dbx
+
+import (
+	"sort"
+	"strings"
+	"testing"
+)
+
+type trackedProfile struct {
+	Tags []string `json:"tags"`
+}
+
+type trackedUser struct {
+	TrackedModel
+	Id      int64                  `db:"id"`
+	Tags    []string               `db:"tags"`
+	Attrs   map[string]interface{} `db:"attrs,json"`
+	Profile *trackedProfile        `db:"profile,json"`
+	Avatar  []byte                 `db:"avatar"`
+}
+
+func TestDirtyFieldsSeeInPlaceEdits(t *testing.T) {
+	newUser := func() *trackedUser {
+		u := &trackedUser{
+			Id:      1,
+			Tags:    []string{"a", "b"},
+			Attrs:   map[string]interface{}{"level": 1, "flags": []interface{}{"x"}},
+			Profile: &trackedProfile{Tags: []string{"p"}},
+			Avatar:  []byte{1, 2},
+		}
+
+		rememberOriginalValues(u)
+		return u
+	}
+
+	cases := map[string]func(u *trackedUser){
+		"Tags":    func(u *trackedUser) { u.Tags[0] = "z" },
+		"Attrs":   func(u *trackedUser) { u.Attrs["flags"].([]interface{})[0] = "y" },
+		"Profile": func(u *trackedUser) { u.Profile.Tags[0] = "q" },
+		"Avatar":  func(u *trackedUser) { u.Avatar[1] = 9 },
+	}
+
+	for want, edit := range cases {
+		u := newUser()
+
+		if fields, _ := getDirtyFields(u); len(fields) > 0 {
+			t.Fatalf("fresh model has dirty fields %v", fields)
+		}
+
+		edit(u)
+		fields, tracked := getDirtyFields(u)
+		sort.Strings(fields)
+
+		if !tracked || strings.Join(fields, ",") != want {
+			t.Fatalf("dirty fields = %v, want [%s]", fields, want)
+		}
+	}
+}
+
+type trackedAccount struct {
+	TrackedModel
+	Id       int64  `db:"id,pk"`
+	Name     string `db:"name"`
+	Balance  int64  `db:"balance,readonly"`
+	UpdateAt string `db:"update_at"`
+}
+
+func TestSaveSkipsUpdateWithoutWritableDirtyFields(t *testing.T) {
+	db := openSqliteTestDb(t,
+		"CREATE TABLE accounts (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT, balance INTEGER, update_at TEXT)",
+		"INSERT INTO accounts (name, balance, update_at) VALUES ('a', 10, 'never')",
+	)
+
+	var acc trackedAccount
+
+	if err := Table("accounts").Where("id", 1).FirstInto(&acc); err != nil {
+		t.Fatal(err)
+	}
+
+	rememberOriginalValues(&acc)
+	acc.Balance = 99
+
+	if n, err := Table("accounts").Save(&acc); err != nil || n != 0 {
+		t.Fatalf("Save() = %d, %v, want 0", n, err)
+	}
+
+	if n := countRows(t, db, "SELECT COUNT(*) FROM accounts WHERE balance = 10 AND update_at = 'never'"); n != 1 {
+		t.Fatal("row was written although only readonly fields changed")
+	}
+
+	acc.Name = "b"
+
+	if n, err := Table("accounts").Save(&acc); err != nil || n != 1 {
+		t.Fatalf("Save() = %d, %v, want 1", n, err)
+	}
+
+	if n := countRows(t, db, "SELECT COUNT(*) FROM accounts WHERE name = 'b' AND balance = 10"); n != 1 {
+		t.Fatal("name was not saved")
+	}
+}
@@ -0,0 +1,223 @@
+package dbx
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+type TypeConverter interface {
+	FromDb(value interface{}) (interface{}, error)
+	ToDb(value interface{}) (interface{}, error)
+}
+
+type funcTypeConverter struct {
+	fromDb func(value interface{}) (interface{}, error)
+	toDb   func(value interface{}) (interface{}, error)
+}
+
+func (c *funcTypeConverter) FromDb(value interface{}) (interface{}, error) {
+	return c.fromDb(value)
+}
+
+func (c *funcTypeConverter) ToDb(value interface{}) (interface{}, error) {
+	return c.toDb(value)
+}
+
+func NewTypeConverter(
+	fromDb func(value interface{}) (interface{}, error),
+	toDb func(value interface{}) (interface{}, error),
+) TypeConverter {
+	return &funcTypeConverter{fromDb: fromDb, toDb: toDb}
+}
+
+var typeConverters = map[reflect.Type]TypeConverter{}
+var typeConvertersLock = &sync.RWMutex{}
+
+var scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+var valuerType = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+var bytesType = reflect.TypeOf([]byte(nil))
+
+func RegisterTypeConverter(sample interface{}, converter TypeConverter) {
+	if sample == nil || converter == nil {
+		return
+	}
+
+	typeConvertersLock.Lock()
+	typeConverters[reflect.TypeOf(sample)] = converter
+	typeConvertersLock.Unlock()
+	clearModelFieldsCache()
+}
+
+func RemoveTypeConverter(sample interface{}) {
+	if sample == nil {
+		return
+	}
+
+	typeConvertersLock.Lock()
+	delete(typeConverters, reflect.TypeOf(sample))
+	typeConvertersLock.Unlock()
+	clearModelFieldsCache()
+}
+
+func getTypeConverter(rt reflect.Type) (TypeConverter, bool) {
+	typeConvertersLock.RLock()
+	defer typeConvertersLock.RUnlock()
+	converter, ok := typeConverters[rt]
+	return converter, ok
+}
+
+func (f *scanField) rawValue() interface{} {
+	switch f.TypeName {
+	case "NullString":
+		if f.NullStringVal.Valid {
+			return f.NullStringVal.String
+		}
+	case "NullBool":
+		if f.NullBoolVal.Valid {
+			return f.NullBoolVal.Bool
+		}
+	case "NullInt32":
+		if f.NullInt32Val.Valid {
+			return int64(f.NullInt32Val.Int32)
+		}
+	case "NullInt64":
+		if f.NullInt64Val.Valid {
+			return f.NullInt64Val.Int64
+		}
+	case "NullFloat64":
+		if f.NullFloat64Val.Valid {
+			return f.NullFloat64Val.Float64
+		}
+	case "NullTime":
+		if f.NullTimeVal.Valid {
+			return f.NullTimeVal.Time
+		}
+	case "string":
+		return f.StringVal
+	case "bool":
+		return f.BoolVal
+	case "int":
+		return int64(f.IntVal)
+	case "int64":
+		return f.Int64Val
+	case "float64":
+		return f.Float64Val
+	case "time":
+		return f.TimeVal
+	case "interface":
+		if buf, ok := f.InterfaceVal.([]byte); ok {
+			return append([]byte{}, buf...)
+		}
+
+		return f.InterfaceVal
+	}
+
+	return nil
+}
+
+func setCustomValueToField(fv reflect.Value, sf *scanField) (bool, error) {
+	if !fv.CanSet() {
+		return false, nil
+	}
+
+	rt := fv.Type()
+
+	if converter, ok := getTypeConverter(rt); ok {
+		value, err := converter.FromDb(sf.rawValue())
+
+		if err != nil {
+			return true, err
+		}
+
+		return true, setReflectValue(fv, value)
+	}
+
+	if reflect.PtrTo(rt).Implements(scannerType) {
+		return true, fv.Addr().Interface().(sql.Scanner).Scan(sf.rawValue())
+	}
+
+	if rt.Kind() == reflect.Ptr && reflect.PtrTo(rt.Elem()).Implements(scannerType) {
+		raw := sf.rawValue()
+
+		if raw == nil {
+			fv.Set(reflect.Zero(rt))
+			return true, nil
+		}
+
+		value := reflect.New(rt.Elem())
+
+		if err := value.Interface().(sql.Scanner).Scan(raw); err != nil {
+			return true, err
+		}
+
+		fv.Set(value)
+		return true, nil
+	}
+
+	if rt == bytesType {
+		switch raw := sf.rawValue().(type) {
+		case []byte:
+			fv.SetBytes(raw)
+		case string:
+			fv.SetBytes([]byte(raw))
+		case nil:
+			fv.SetBytes(nil)
+		}
+
+		return true, nil
+	}
+
+	return false, nil
+}
+
+func setReflectValue(fv reflect.Value, value interface{}) error {
+	if value == nil {
+		fv.Set(reflect.Zero(fv.Type()))
+		return nil
+	}
+
+	rv := reflect.ValueOf(value)
+
+	if rv.Type().AssignableTo(fv.Type()) {
+		fv.Set(rv)
+		return nil
+	}
+
+	if rv.Type().ConvertibleTo(fv.Type()) {
+		fv.Set(rv.Convert(fv.Type()))
+		return nil
+	}
+
+	return NewDbException(fmt.Sprintf("cannot assign value of type %v to field of type %v", rv.Type(), fv.Type()))
+}
+
+func getModelFieldValue(fv reflect.Value) (interface{}, error) {
+	if converter, ok := getTypeConverter(fv.Type()); ok {
+		return converter.ToDb(fv.Interface())
+	}
+
+	if fv.Kind() == reflect.Ptr && fv.IsNil() {
+		return nil, nil
+	}
+
+	if fv.Type().Implements(valuerType) {
+		return fv.Interface().(driver.Valuer).Value()
+	}
+
+	if fv.Kind() == reflect.Ptr {
+		fv = fv.Elem()
+
+		if converter, ok := getTypeConverter(fv.Type()); ok {
+			return converter.ToDb(fv.Interface())
+		}
+	}
+
+	if fv.CanAddr() && reflect.PtrTo(fv.Type()).Implements(valuerType) {
+		return fv.Addr().Interface().(driver.Valuer).Value()
+	}
+
+	return fv.Interface(), nil
+}
@@ -0,0 +1,78 @@
+package dbx
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+type tcMoney struct {
+	Cents int64
+}
+
+type tcProduct struct {
+	Id    int64   `db:"id,pk,autoincr"`
+	Name  string  `db:"name"`
+	Price tcMoney `db:"price"`
+}
+
+type tcInvoice struct {
+	Total tcMoney `db:",prefix=total_"`
+}
+
+var tcMoneyConverter = NewTypeConverter(
+	func(value interface{}) (interface{}, error) {
+		var units, cents int64
+
+		if _, err := fmt.Sscanf(fmt.Sprint(value), "%d.%02d", &units, &cents); err != nil {
+			return nil, err
+		}
+
+		return tcMoney{Cents: units*100 + cents}, nil
+	},
+	func(value interface{}) (interface{}, error) {
+		m := value.(tcMoney)
+		return fmt.Sprintf("%d.%02d", m.Cents/100, m.Cents%100), nil
+	},
+)
+
+func TestTypeConverterRoundTrip(t *testing.T) {
+	db := openSqliteTestDb(t, "CREATE TABLE products (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT, price TEXT)")
+
+	if fields := getModelFields(reflect.TypeOf(tcInvoice{})); len(fields) != 1 || fields[0].name != "Total.Cents" {
+		t.Fatalf("unregistered struct was not flattened: %+v", fields)
+	}
+
+	RegisterTypeConverter(tcMoney{}, tcMoneyConverter)
+	t.Cleanup(func() { RemoveTypeConverter(tcMoney{}) })
+
+	if fields := getModelFields(reflect.TypeOf(tcInvoice{})); len(fields) != 1 || fields[0].name != "Total" {
+		t.Fatalf("cached fields survived RegisterTypeConverter: %+v", fields)
+	}
+
+	p := &tcProduct{Name: "pen", Price: tcMoney{Cents: 1234}}
+
+	if _, err := Table("products").InsertByModel(p); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := countRows(t, db, "SELECT COUNT(*) FROM products WHERE price = '12.34'"); n != 1 {
+		t.Fatal("ToDb was not applied on insert")
+	}
+
+	var got tcProduct
+
+	if err := Table("products").Where("id", p.Id).FirstInto(&got); err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Price.Cents != 1234 {
+		t.Fatalf("FromDb result = %+v, want 1234 cents", got.Price)
+	}
+
+	RemoveTypeConverter(tcMoney{})
+
+	if fields := getModelFields(reflect.TypeOf(tcInvoice{})); fields[0].name != "Total.Cents" {
+		t.Fatalf("cached fields survived RemoveTypeConverter: %+v", fields)
+	}
+}
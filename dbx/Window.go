@@ -0,0 +1,169 @@
+package dbx
+
+import (
+	"regexp"
+	"strings"
+)
+
+var regexpWindowFunc = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+var regexpWindowArg = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?$`)
+var regexpWindowFrame = regexp.MustCompile(
+	`^(ROWS|RANGE)[\x20\t]+(BETWEEN[\x20\t]+(UNBOUNDED[\x20\t]+PRECEDING|CURRENT[\x20\t]+ROW|[0-9]+[\x20\t]+(PRECEDING|FOLLOWING))[\x20\t]+AND[\x20\t]+(UNBOUNDED[\x20\t]+FOLLOWING|CURRENT[\x20\t]+ROW|[0-9]+[\x20\t]+(PRECEDING|FOLLOWING))|UNBOUNDED[\x20\t]+PRECEDING|CURRENT[\x20\t]+ROW|[0-9]+[\x20\t]+PRECEDING)$`,
+)
+
+type windowExpr struct {
+	fn          string
+	args        []string
+	partitionBy []string
+	orderBy     []string
+	frame       string
+	alias       string
+}
+
+func Window(fn string, args ...string) *windowExpr {
+	return &windowExpr{fn: strings.ToUpper(strings.TrimSpace(fn)), args: args}
+}
+
+func (w *windowExpr) PartitionBy(columnNames ...string) *windowExpr {
+	for _, columnName := range columnNames {
+		for _, s1 := range regexpCommaSep.Split(strings.TrimSpace(columnName), -1) {
+			if s1 != "" {
+				w.partitionBy = append(w.partitionBy, s1)
+			}
+		}
+	}
+
+	return w
+}
+
+func (w *windowExpr) OrderBy(orderBy ...string) *windowExpr {
+	for _, item := range orderBy {
+		for _, s1 := range regexpCommaSep.Split(strings.TrimSpace(item), -1) {
+			if s1 != "" {
+				w.orderBy = append(w.orderBy, s1)
+			}
+		}
+	}
+
+	return w
+}
+
+func (w *windowExpr) Frame(frame string) *windowExpr {
+	frame = strings.ToUpper(strings.TrimSpace(frame))
+
+	if regexpWindowFrame.MatchString(frame) {
+		w.frame = regexpSpace.ReplaceAllString(frame, " ")
+	}
+
+	return w
+}
+
+func (w *windowExpr) As(alias string) *windowExpr {
+	w.alias = strings.TrimSpace(alias)
+	return w
+}
+
+func (w *windowExpr) expr() string {
+	if !regexpWindowFunc.MatchString(w.fn) {
+		return ""
+	}
+
+	args := make([]string, 0, len(w.args))
+
+	for _, arg := range w.args {
+		arg = strings.TrimSpace(arg)
+
+		switch {
+		case arg == "":
+			continue
+		case arg == "*", regexpWindowArg.MatchString(arg):
+			args = append(args, arg)
+		case isValidIdentifier(arg, false):
+			args = append(args, quote(arg))
+		default:
+			return ""
+		}
+	}
+
+	sb := strings.Builder{}
+	sb.WriteString(w.fn + "(" + strings.Join(args, ", ") + ") OVER (")
+	clauses := make([]string, 0, 3)
+
+	if len(w.partitionBy) > 0 {
+		columnNames := make([]string, 0, len(w.partitionBy))
+
+		for _, columnName := range w.partitionBy {
+			if !isValidIdentifier(columnName, false) {
+				return ""
+			}
+
+			columnNames = append(columnNames, quote(columnName))
+		}
+
+		clauses = append(clauses, "PARTITION BY "+strings.Join(columnNames, ", "))
+	}
+
+	if len(w.orderBy) > 0 {
+		orderBy := make([]string, 0, len(w.orderBy))
+
+		for _, item := range w.orderBy {
+			s1, ok := parseOrderBy(item)
+
+			if !ok {
+				return ""
+			}
+
+			orderBy = append(orderBy, s1)
+		}
+
+		clauses = append(clauses, "ORDER BY "+strings.Join(orderBy, ", "))
+	}
+
+	if w.frame != "" {
+		clauses = append(clauses, w.frame)
+	}
+
+	sb.WriteString(strings.Join(clauses, " "))
+	sb.WriteString(")")
+	return sb.String()
+}
+
+func (w *windowExpr) toColumn() (column, bool) {
+	expr := w.expr()
+
+	if expr == "" || (w.alias != "" && !isValidAlias(w.alias)) {
+		return column{}, false
+	}
+
+	alias := w.alias
+
+	if alias != "" {
+		alias = quote(alias)
+	}
+
+	return column{expr: expr, alias: alias}, true
+}
+
+func (qb *queryBuilder) addWindowColumn(w *windowExpr) *queryBuilder {
+	if w == nil {
+		return qb
+	}
+
+	item, ok := w.toColumn()
+
+	if !ok {
+		return qb.setErr(NewDbException("invalid window expression"))
+	}
+
+	if w.alias != "" {
+		for idx, c := range qb.columns {
+			if c.expr != "" && c.alias == item.alias {
+				qb.columns[idx] = item
+				return qb
+			}
+		}
+	}
+
+	qb.columns = append(qb.columns, item)
+	return qb
+}
@@ -4,15 +4,13 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
-	"fmt"
 	"github.com/go-errors/errors"
-	"github.com/meiguonet/mgboot-go-common/util/slicex"
 	"strings"
 	"unicode/utf8"
 )
 
-func Raw(expr string) *rawSql {
-	return &rawSql{expr: expr}
+func Raw(expr string, bindings ...interface{}) *rawSql {
+	return &rawSql{expr: expr, bindings: bindings}
 }
 
 func Table(name string) *queryBuilder {
@@ -122,125 +120,80 @@ func Transations(fn func(tx *sql.Tx) error, opts ...*sql.TxOptions) error {
 	return nil
 }
 
-func BuildTableSchemas() {
+func BuildTableSchemas() error {
 	if pool == nil {
-		return
+		err := NewDbException("database connection pool is nil")
+		writeLog("error", err)
+		return err
 	}
 
-	tableSchemas = map[string][]tableFieldInfo{}
-	rows, err := pool.Query("SHOW TABLES")
+	tableNames, err := getDialect().ListTables(pool)
 
 	if err != nil {
-		return
-	}
-
-	defer rows.Close()
-	var tableNames []string
-
-	for rows.Next() {
-		var tableName string
-		err := rows.Scan(&tableName)
-
-		if err != nil || tableName == "" {
-			continue
-		}
-
-		tableNames = append(tableNames, tableName)
-	}
-
-	if len(tableNames) < 1 {
-		return
+		writeLog("error", err)
+		return toDbException(err)
 	}
 
-	fieldNames := []string{
-		"ctime",
-		"create_at",
-		"createAt",
-		"create_time",
-		"createTime",
-		"update_at",
-		"updateAt",
-		"delete_at",
-		"deleteAt",
-		"del_flag",
-		"delFlag",
-	}
+	schemas := map[string]*TableSchema{}
 
 	for _, tableName := range tableNames {
-		rs, err := pool.Query(fmt.Sprintf("DESC `%s`", tableName))
+		schema, err := getDialect().DescribeTable(pool, tableName)
 
 		if err != nil {
-			continue
+			writeLog("error", err)
+			return toDbException(err)
 		}
 
-		infoList := make([]tableFieldInfo, 0)
-
-		for rs.Next() {
-			var fField string
-			var fType string
-			var fNull string
-			var fKey string
-			var fDefault interface{}
-			var fExtra string
-
-			err := rs.Scan(&fField, &fType, &fNull, &fKey, &fDefault, &fExtra)
+		schemas[tableName] = schema
+	}
 
-			if err != nil {
-				continue
-			}
+	tableSchemasLock.Lock()
+	tableSchemas = schemas
+	tableSchemasLock.Unlock()
+	return nil
+}
 
-			if fField == "" || !slicex.InStringSlice(fField, fieldNames) {
-				continue
-			}
+func RefreshTableSchema(tableName string) error {
+	if pool == nil {
+		err := NewDbException("database connection pool is nil")
+		writeLog("error", err)
+		return err
+	}
 
-			nullable := strings.Contains(strings.ToUpper(fNull), "YES")
-			isPrimaryKey := strings.Contains(strings.ToUpper(fKey), "PRI")
-			defaultValue := toString(fDefault)
-			autoIncrement := strings.Contains(fExtra, "auto_increment")
-			unsigned := strings.Contains(fType, "unsigned")
-			var fieldType string
-			var fieldSize string
-
-			if strings.Contains(fType, " ") {
-				fieldType = substringBefore(fType, " ")
-			} else {
-				fieldType = fType
-			}
+	tableName = normalizeTableName(tableName)
+	schema, err := getDialect().DescribeTable(pool, tableName)
 
-			if strings.HasSuffix(fieldType, ")") {
-				fieldSize = substringAfter(fieldType, "(")
-				fieldSize = strings.TrimSuffix(fieldSize, ")")
-				fieldType = substringBefore(fieldType, "(")
-			} else {
-				fieldSize = "0"
-			}
+	if err != nil {
+		writeLog("error", err)
+		return toDbException(err)
+	}
 
-			infoList = append(infoList, tableFieldInfo{
-				FieldName:     fField,
-				FieldType:     fieldType,
-				FieldSize:     toInt(fieldSize),
-				Unsigned:      unsigned,
-				Nullable:      nullable,
-				DefaultValue:  defaultValue,
-				AutoIncrement: autoIncrement,
-				IsPrimaryKey:  isPrimaryKey,
-			})
-		}
+	tableSchemasLock.Lock()
+	tableSchemas[tableName] = schema
+	tableSchemasLock.Unlock()
+	return nil
+}
 
-		rs.Close()
+func GetTableSchemas() map[string]TableSchema {
+	tableSchemasLock.RLock()
+	defer tableSchemasLock.RUnlock()
+	map1 := make(map[string]TableSchema, len(tableSchemas))
 
-		if len(infoList) > 0 {
-			tableSchemas[tableName] = infoList
-		}
+	for tableName, schema := range tableSchemas {
+		map1[tableName] = schema.clone()
 	}
+
+	return map1
 }
 
-func GetTableSchemas() map[string][]tableFieldInfo {
-	if len(tableSchemas) < 1 {
-		return map[string][]tableFieldInfo{}
+func GetTableSchema(tableName string) (TableSchema, bool) {
+	schema := getTableSchema(tableName)
+
+	if schema == nil {
+		return TableSchema{}, false
 	}
 
-	return tableSchemas
+	return schema.clone(), true
 }
 
 func doSelectBySql(tx *sql.Tx, query string, args ...interface{}) ([]map[string]interface{}, error) {
@@ -252,9 +205,9 @@ func doSelectBySql(tx *sql.Tx, query string, args ...interface{}) ([]map[string]
 		return emptyList, err
 	}
 
-	params, timeout := getParamsAndTimeout(args)
+	params, timeout, parentCtx := getParamsTimeoutAndContext(args)
 	logSql(query, params)
-	ctx, cancel := context.WithTimeout(context.TODO(), timeout)
+	ctx, cancel := context.WithTimeout(parentCtx, timeout)
 	defer cancel()
 	var rows *sql.Rows
 	var err error
@@ -292,10 +245,15 @@ func doInsertBySql(tx *sql.Tx, query string, args ...interface{}) (int64, error)
 		return 0, err
 	}
 
-	params, timeout := getParamsAndTimeout(args)
+	params, timeout, parentCtx := getParamsTimeoutAndContext(args)
 	logSql(query, params)
-	ctx, cancel := context.WithTimeout(context.TODO(), timeout)
+	ctx, cancel := context.WithTimeout(parentCtx, timeout)
 	defer cancel()
+
+	if !getDialect().SupportsLastInsertId() {
+		return doInsertReturningBySql(ctx, tx, query, params)
+	}
+
 	var result sql.Result
 	var err error
 
@@ -325,6 +283,47 @@ func doInsertBySql(tx *sql.Tx, query string, args ...interface{}) (int64, error)
 	return n1, nil
 }
 
+func doInsertReturningBySql(ctx context.Context, tx *sql.Tx, query string, params []interface{}) (int64, error) {
+	var err error
+
+	if !regexpReturning.MatchString(query) {
+		if tx != nil {
+			_, err = tx.ExecContext(ctx, query, params...)
+		} else {
+			_, err = pool.ExecContext(ctx, query, params...)
+		}
+
+		if err != nil {
+			writeLog("error", err)
+			return 0, toDbException(err)
+		}
+
+		return 0, nil
+	}
+
+	var row *sql.Row
+
+	if tx != nil {
+		row = tx.QueryRowContext(ctx, query, params...)
+	} else {
+		row = pool.QueryRowContext(ctx, query, params...)
+	}
+
+	var n1 int64
+
+	// ON CONFLICT DO NOTHING ... RETURNING yields no row when the insert is skipped
+	if err = row.Scan(&n1); err == sql.ErrNoRows {
+		return 0, nil
+	}
+
+	if err != nil {
+		writeLog("error", err)
+		return 0, toDbException(err)
+	}
+
+	return n1, nil
+}
+
 func doUpdateBySql(tx *sql.Tx, query string, args ...interface{}) (int64, error) {
 	if pool == nil {
 		err := NewDbException("database connection pool is nil")
@@ -332,9 +331,9 @@ func doUpdateBySql(tx *sql.Tx, query string, args ...interface{}) (int64, error)
 		return 0, err
 	}
 
-	params, timeout := getParamsAndTimeout(args)
+	params, timeout, parentCtx := getParamsTimeoutAndContext(args)
 	logSql(query, params)
-	ctx, cancel := context.WithTimeout(context.TODO(), timeout)
+	ctx, cancel := context.WithTimeout(parentCtx, timeout)
 	defer cancel()
 	var result sql.Result
 	n1 := int64(-1)
@@ -373,9 +372,9 @@ func doExecuteSql(tx *sql.Tx, query string, args ...interface{}) error {
 		return err
 	}
 
-	params, timeout := getParamsAndTimeout(args)
+	params, timeout, parentCtx := getParamsTimeoutAndContext(args)
 	logSql(query, params)
-	ctx, cancel := context.WithTimeout(context.TODO(), timeout)
+	ctx, cancel := context.WithTimeout(parentCtx, timeout)
 	defer cancel()
 	var err error
 
@@ -1,11 +1,12 @@
 package dbx
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
-	"github.com/meiguonet/mgboot-go-common/util/slicex"
 	"reflect"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -18,8 +19,9 @@ const (
 
 var regexpAS = regexp.MustCompile(`(?i)[\x20\t]+as[\x20\t]+`)
 var regexpSpace = regexp.MustCompile(`[\x20\t]+`)
-var regexpGormColumn = regexp.MustCompile(`column[\x20\t]*:[\x20\t]*(^[\x20\t;]+)`)
+var regexpGormColumn = regexp.MustCompile(`column[\x20\t]*:[\x20\t]*([^\x20\t;]+)`)
 var regexpCommaSep = regexp.MustCompile(`[\x20\t]*,[\x20\t]*`)
+var regexpReturning = regexp.MustCompile(`(?i)[\x20\t\n]+RETURNING[\x20\t\n]+`)
 
 func parseToNameAndAlias(str string) (string, string) {
 	var parts []string
@@ -41,150 +43,159 @@ func parseToNameAndAlias(str string) (string, string) {
 	return parts[0], ""
 }
 
+func getResultColumnName(columnName string) string {
+	name, alias := parseToNameAndAlias(strings.TrimSpace(columnName))
+
+	if alias != "" {
+		return alias
+	}
+
+	if strings.Contains(name, ".") {
+		name = substringAfter(name, ".")
+	}
+
+	return strings.NewReplacer("`", "", `"`, "").Replace(name)
+}
+
 func quote(str string) string {
-	str = strings.ReplaceAll(str, "`", "")
+	d := getDialect()
+	str = strings.NewReplacer("`", "", `"`, "").Replace(str)
 
 	if !strings.Contains(str, ".") {
 		if str == "*" {
 			return str
 		}
 
-		return "`" + str + "`"
+		return d.QuoteIdentifier(str)
 	}
 
-	p1 := substringBefore(str, ".")
-	p2 := substringAfter(str, ".")
+	parts := strings.Split(str, ".")
 
-	if p2 != "*" {
-		p2 = ensureLeft(p2, "`")
-		p2 = ensureRight(p2, "`")
+	for idx, part := range parts {
+		if part != "*" {
+			parts[idx] = d.QuoteIdentifier(part)
+		}
 	}
 
-	return p1 + "." + p2
+	return strings.Join(parts, ".")
 }
 
-func parseOrderBy(orderBy string) string {
-	parts := regexpSpace.Split(orderBy, -1)
+func parseOrderBy(orderBy string) (string, bool) {
+	parts := regexpSpace.Split(strings.TrimSpace(orderBy), -1)
+
+	if len(parts) > 2 || !isValidIdentifier(parts[0], false) {
+		return "", false
+	}
 
 	if len(parts) < 2 {
-		return quote(parts[0]) + " ASC"
+		return quote(parts[0]) + " ASC", true
+	}
+
+	direction := strings.ToUpper(parts[1])
+
+	if direction != "ASC" && direction != "DESC" {
+		return "", false
 	}
 
-	return quote(parts[0]) + " " + strings.ToUpper(parts[1])
+	return quote(parts[0]) + " " + direction, true
 }
 
 func buildScanFields(rs *sql.Rows) (scanFields []*scanField, scanArgs []interface{}) {
+	return newScanFields(getScanTypeNames(rs))
+}
+
+func getScanTypeNames(rs *sql.Rows) (typeNames []string) {
 	defer func() {
 		if r := recover(); r != nil {
-			scanFields = make([]*scanField, 0)
-			scanArgs = make([]interface{}, 0)
+			typeNames = make([]string, 0)
 		}
 	}()
 
 	columnTypes, _ := rs.ColumnTypes()
-	n1 := len(columnTypes)
-	scanFields = make([]*scanField, n1, n1)
-	scanArgs = make([]interface{}, n1, n1)
+	typeNames = make([]string, len(columnTypes))
 
 	for idx, columnType := range columnTypes {
 		scanType := columnType.ScanType().Name()
 
-		if strings.Contains(scanType, "NullString") {
-			field := &scanField{TypeName: "NullString"}
-			scanFields[idx] = field
-			scanArgs[idx] = &field.NullStringVal
-			continue
-		}
-
-		if strings.Contains(scanType, "NullBool") {
-			field := &scanField{TypeName: "NullBool"}
-			scanFields[idx] = field
-			scanArgs[idx] = &field.NullBoolVal
-			continue
-		}
-
-		if strings.Contains(scanType, "NullInt32") {
-			field := &scanField{TypeName: "NullInt32"}
-			scanFields[idx] = field
-			scanArgs[idx] = &field.NullInt32Val
-			continue
+		switch {
+		case strings.Contains(scanType, "NullString"):
+			typeNames[idx] = "NullString"
+		case strings.Contains(scanType, "NullBool"):
+			typeNames[idx] = "NullBool"
+		case strings.Contains(scanType, "NullInt32"):
+			typeNames[idx] = "NullInt32"
+		case strings.Contains(scanType, "NullInt64"):
+			typeNames[idx] = "NullInt64"
+		case strings.Contains(scanType, "NullFloat64"):
+			typeNames[idx] = "NullFloat64"
+		case strings.Contains(scanType, "NullTime"):
+			typeNames[idx] = "NullTime"
+		default:
+			typeNames[idx] = getLowerScanTypeName(strings.ToLower(scanType))
 		}
+	}
 
-		if strings.Contains(scanType, "NullInt64") {
-			field := &scanField{TypeName: "NullInt64"}
-			scanFields[idx] = field
-			scanArgs[idx] = &field.NullInt64Val
-			continue
-		}
+	return
+}
 
-		if strings.Contains(scanType, "NullFloat64") {
-			field := &scanField{TypeName: "NullFloat64"}
-			scanFields[idx] = field
-			scanArgs[idx] = &field.NullFloat64Val
-			continue
-		}
+func getLowerScanTypeName(scanType string) string {
+	switch {
+	case strings.Contains(scanType, "rawbytes"):
+		return "NullString"
+	case strings.Contains(scanType, "string"):
+		return "string"
+	case strings.Contains(scanType, "bool"):
+		return "bool"
+	case strings.Contains(scanType, "int64"):
+		return "int64"
+	case strings.Contains(scanType, "int"):
+		return "int"
+	case strings.Contains(scanType, "float"):
+		return "float64"
+	case strings.Contains(scanType, "time"):
+		return "time"
+	}
+
+	return "interface"
+}
 
-		if strings.Contains(scanType, "NullTime") {
-			field := &scanField{TypeName: "NullTime"}
-			scanFields[idx] = field
-			scanArgs[idx] = &field.NullTimeVal
-			continue
-		}
+func newScanFields(typeNames []string) (scanFields []*scanField, scanArgs []interface{}) {
+	n1 := len(typeNames)
+	scanFields = make([]*scanField, n1, n1)
+	scanArgs = make([]interface{}, n1, n1)
 
-		scanType = strings.ToLower(scanType)
+	for idx, typeName := range typeNames {
+		field := &scanField{TypeName: typeName}
+		scanFields[idx] = field
 
-		if strings.Contains(scanType, "rawbytes") {
-			field := &scanField{TypeName: "NullString"}
-			scanFields[idx] = field
+		switch typeName {
+		case "NullString":
 			scanArgs[idx] = &field.NullStringVal
-			continue
-		}
-
-		if strings.Contains(scanType, "string") {
-			field := &scanField{TypeName: "string"}
-			scanFields[idx] = field
+		case "NullBool":
+			scanArgs[idx] = &field.NullBoolVal
+		case "NullInt32":
+			scanArgs[idx] = &field.NullInt32Val
+		case "NullInt64":
+			scanArgs[idx] = &field.NullInt64Val
+		case "NullFloat64":
+			scanArgs[idx] = &field.NullFloat64Val
+		case "NullTime":
+			scanArgs[idx] = &field.NullTimeVal
+		case "string":
 			scanArgs[idx] = &field.StringVal
-			continue
-		}
-
-		if strings.Contains(scanType, "bool") {
-			field := &scanField{TypeName: "bool"}
-			scanFields[idx] = field
+		case "bool":
 			scanArgs[idx] = &field.BoolVal
-			continue
-		}
-
-		if strings.Contains(scanType, "int64") {
-			field := &scanField{TypeName: "int64"}
-			scanFields[idx] = field
+		case "int64":
 			scanArgs[idx] = &field.Int64Val
-			continue
-		}
-
-		if strings.Contains(scanType, "int") {
-			field := &scanField{TypeName: "int"}
-			scanFields[idx] = field
+		case "int":
 			scanArgs[idx] = &field.IntVal
-			continue
-		}
-
-		if strings.Contains(scanType, "float") {
-			field := &scanField{TypeName: "float64"}
-			scanFields[idx] = field
+		case "float64":
 			scanArgs[idx] = &field.Float64Val
-			continue
-		}
-
-		if strings.Contains(scanType, "time") {
-			field := &scanField{TypeName: "time"}
-			scanFields[idx] = field
+		case "time":
 			scanArgs[idx] = &field.TimeVal
-			continue
+		default:
+			scanArgs[idx] = &field.InterfaceVal
 		}
-
-		field := &scanField{TypeName: "interface"}
-		scanFields[idx] = field
-		scanArgs[idx] = &field.InterfaceVal
 	}
 
 	return
@@ -349,390 +360,328 @@ func scanIntoMap(rs *sql.Rows) (map[string]interface{}, error) {
 }
 
 func scanIntoModel(rs *sql.Rows, model interface{}) error {
-	err1 := NewDbException("model is not struct pointer")
-
-	if model == nil {
-		return err1
-	}
-
-	rt := reflect.TypeOf(model)
-
-	if rt.Kind() != reflect.Ptr || rt.Elem().Kind() != reflect.Struct {
-		return err1
-	}
-
-	columnNames, err := rs.Columns()
+	scanner, err := newModelScanner(rs, reflect.TypeOf(model))
 
 	if err != nil {
 		return err
 	}
 
-	scanFields, scanArgs := buildScanFields(rs)
-
-	if len(scanFields) < 1 || len(scanArgs) < 1 || len(scanFields) != len(scanArgs) || len(scanFields) != len(columnNames) {
-		return NewDbException("scan error")
-	}
+	return scanner.scan(rs, model)
+}
 
-	for _, scanField := range scanFields {
-		if scanField == nil {
-			return NewDbException("scan error")
-		}
-	}
+func setScanFieldToModelField(fv reflect.Value, mf modelField, scanField *scanField) error {
+	field := mf.field
 
-	for _, scanArg := range scanArgs {
-		if scanArg == nil {
-			return NewDbException("scan error")
-		}
+	if mf.json {
+		return unmarshalJsonField(fv, scanField.rawValue())
 	}
 
-	if err := rs.Scan(scanArgs...); err != nil {
+	if ok, err := setCustomValueToField(fv, scanField); ok {
 		return err
 	}
 
-	rt = rt.Elem()
-	ptr := getStructPtr(model)
-
-	for i := 0; i < rt.NumField(); i++ {
-		field := rt.Field(i)
-		idx := getColumnIdxByGormTag(field.Tag, columnNames)
-
-		if idx < 0 {
-			idx = getColumnIdxByStructFieldName(field.Name, columnNames)
-		}
+	switch field.Type.Kind() {
+	case reflect.String:
+		switch scanField.TypeName {
+		case "NullString":
+			if scanField.NullStringVal.Valid {
+				setStringToStructField(fv, scanField.NullStringVal.String)
+			}
 
-		if idx < 0 {
-			continue
+			break
+		case "string":
+			setStringToStructField(fv, scanField.StringVal)
+			break
 		}
 
-		scanField := scanFields[idx]
-
-		switch field.Type.Kind() {
-		case reflect.String:
-			switch scanField.TypeName {
-			case "NullString":
-				if scanField.NullStringVal.Valid {
-					setStringToStructField(ptr, field, scanField.NullStringVal.String)
-				}
-
-				break
-			case "string":
-				setStringToStructField(ptr, field, scanField.StringVal)
-				break
+		return nil
+	case reflect.Bool:
+		switch scanField.TypeName {
+		case "NullBool":
+			if scanField.NullBoolVal.Valid {
+				setBoolToStructField(fv, scanField.NullBoolVal.Bool)
 			}
 
-			continue
-		case reflect.Bool:
-			switch scanField.TypeName {
-			case "NullBool":
-				if scanField.NullBoolVal.Valid {
-					setBoolToStructField(ptr, field, scanField.NullBoolVal.Bool)
-				}
+			break
+		case "bool":
+			setBoolToStructField(fv, scanField.BoolVal)
+			break
+		case "int":
+			setBoolToStructField(fv, scanField.IntVal == 1)
+			break
+		}
 
-				break
-			case "bool":
-				setBoolToStructField(ptr, field, scanField.BoolVal)
-				break
-			case "int":
-				setBoolToStructField(ptr, field, scanField.IntVal == 1)
-				break
+		return nil
+	case reflect.Int:
+		switch scanField.TypeName {
+		case "NullInt64":
+			if scanField.NullInt64Val.Valid {
+				setIntToStructField(fv, int(scanField.NullInt64Val.Int64))
 			}
 
-			continue
-		case reflect.Int:
-			switch scanField.TypeName {
-			case "NullInt64":
-				if scanField.NullInt64Val.Valid {
-					setIntToStructField(ptr, field, int(scanField.NullInt64Val.Int64))
-				}
-
-				break
-			case "NullInt32":
-				if scanField.NullInt32Val.Valid {
-					setIntToStructField(ptr, field, int(scanField.NullInt32Val.Int32))
-				}
-
-				break
-			case "int64":
-				setIntToStructField(ptr, field, int(scanField.Int64Val))
-				break
-			case "int":
-				setIntToStructField(ptr, field, scanField.IntVal)
-				break
-			case "string":
-				if n1, err := strconv.Atoi(scanField.StringVal); err == nil {
-					setIntToStructField(ptr, field, n1)
-				}
+			break
+		case "NullInt32":
+			if scanField.NullInt32Val.Valid {
+				setIntToStructField(fv, int(scanField.NullInt32Val.Int32))
+			}
 
-				break
+			break
+		case "int64":
+			setIntToStructField(fv, int(scanField.Int64Val))
+			break
+		case "int":
+			setIntToStructField(fv, scanField.IntVal)
+			break
+		case "string":
+			if n1, err := strconv.Atoi(scanField.StringVal); err == nil {
+				setIntToStructField(fv, n1)
 			}
 
-			continue
-		case reflect.Int32:
-			switch scanField.TypeName {
-			case "NullInt64":
-				if scanField.NullInt64Val.Valid {
-					setInt32ToStructField(ptr, field, int32(scanField.NullInt64Val.Int64))
-				}
+			break
+		}
 
-				break
-			case "NullInt32":
-				if scanField.NullInt32Val.Valid {
-					setInt32ToStructField(ptr, field, scanField.NullInt32Val.Int32)
-				}
+		return nil
+	case reflect.Int32:
+		switch scanField.TypeName {
+		case "NullInt64":
+			if scanField.NullInt64Val.Valid {
+				setInt32ToStructField(fv, int32(scanField.NullInt64Val.Int64))
+			}
 
-				break
-			case "int64":
-				setInt32ToStructField(ptr, field, int32(scanField.Int64Val))
-				break
-			case "int":
-				setInt32ToStructField(ptr, field, int32(scanField.IntVal))
-				break
-			case "string":
-				if n1, err := strconv.ParseInt(scanField.StringVal, 10, 32); err == nil {
-					setInt32ToStructField(ptr, field, int32(n1))
-				}
+			break
+		case "NullInt32":
+			if scanField.NullInt32Val.Valid {
+				setInt32ToStructField(fv, scanField.NullInt32Val.Int32)
+			}
 
-				break
+			break
+		case "int64":
+			setInt32ToStructField(fv, int32(scanField.Int64Val))
+			break
+		case "int":
+			setInt32ToStructField(fv, int32(scanField.IntVal))
+			break
+		case "string":
+			if n1, err := strconv.ParseInt(scanField.StringVal, 10, 32); err == nil {
+				setInt32ToStructField(fv, int32(n1))
 			}
 
-			continue
-		case reflect.Int64:
-			switch scanField.TypeName {
-			case "NullInt64":
-				if scanField.NullInt64Val.Valid {
-					setInt64ToStructField(ptr, field, scanField.NullInt64Val.Int64)
-				}
+			break
+		}
 
-				break
-			case "NullInt32":
-				if scanField.NullInt32Val.Valid {
-					setInt64ToStructField(ptr, field, int64(scanField.NullInt32Val.Int32))
-				}
+		return nil
+	case reflect.Int64:
+		switch scanField.TypeName {
+		case "NullInt64":
+			if scanField.NullInt64Val.Valid {
+				setInt64ToStructField(fv, scanField.NullInt64Val.Int64)
+			}
 
-				break
-			case "int64":
-				setInt64ToStructField(ptr, field, scanField.Int64Val)
-			case "int":
-				setInt64ToStructField(ptr, field, int64(scanField.IntVal))
-				break
-			case "string":
-				if n1, err := strconv.ParseInt(scanField.StringVal, 10, 64); err == nil {
-					setInt64ToStructField(ptr, field, n1)
-				}
+			break
+		case "NullInt32":
+			if scanField.NullInt32Val.Valid {
+				setInt64ToStructField(fv, int64(scanField.NullInt32Val.Int32))
+			}
 
-				break
+			break
+		case "int64":
+			setInt64ToStructField(fv, scanField.Int64Val)
+		case "int":
+			setInt64ToStructField(fv, int64(scanField.IntVal))
+			break
+		case "string":
+			if n1, err := strconv.ParseInt(scanField.StringVal, 10, 64); err == nil {
+				setInt64ToStructField(fv, n1)
 			}
 
-			continue
-		case reflect.Uint:
-			switch scanField.TypeName {
-			case "NullInt64":
-				if scanField.NullInt64Val.Valid {
-					setUintToStructField(ptr, field, uint(scanField.NullInt64Val.Int64))
-				}
+			break
+		}
 
-				break
-			case "NullInt32":
-				if scanField.NullInt32Val.Valid {
-					setUintToStructField(ptr, field, uint(scanField.NullInt32Val.Int32))
-				}
+		return nil
+	case reflect.Uint:
+		switch scanField.TypeName {
+		case "NullInt64":
+			if scanField.NullInt64Val.Valid {
+				setUintToStructField(fv, uint(scanField.NullInt64Val.Int64))
+			}
 
-				break
-			case "int64":
-				setUintToStructField(ptr, field, uint(scanField.Int64Val))
-				break
-			case "int":
-				setUintToStructField(ptr, field, uint(scanField.IntVal))
-				break
-			case "string":
-				if n1, err := strconv.Atoi(scanField.StringVal); err == nil {
-					setUintToStructField(ptr, field, uint(n1))
-				}
+			break
+		case "NullInt32":
+			if scanField.NullInt32Val.Valid {
+				setUintToStructField(fv, uint(scanField.NullInt32Val.Int32))
+			}
 
-				break
+			break
+		case "int64":
+			setUintToStructField(fv, uint(scanField.Int64Val))
+			break
+		case "int":
+			setUintToStructField(fv, uint(scanField.IntVal))
+			break
+		case "string":
+			if n1, err := strconv.Atoi(scanField.StringVal); err == nil {
+				setUintToStructField(fv, uint(n1))
 			}
 
-			continue
-		case reflect.Uint32:
-			switch scanField.TypeName {
-			case "NullInt64":
-				if scanField.NullInt64Val.Valid {
-					setUint32ToStructField(ptr, field, uint32(scanField.NullInt64Val.Int64))
-				}
+			break
+		}
 
-				break
-			case "NullInt32":
-				if scanField.NullInt32Val.Valid {
-					setUint32ToStructField(ptr, field, uint32(scanField.NullInt32Val.Int32))
-				}
+		return nil
+	case reflect.Uint32:
+		switch scanField.TypeName {
+		case "NullInt64":
+			if scanField.NullInt64Val.Valid {
+				setUint32ToStructField(fv, uint32(scanField.NullInt64Val.Int64))
+			}
 
-				break
-			case "int64":
-				setUint32ToStructField(ptr, field, uint32(scanField.Int64Val))
-				break
-			case "int":
-				setUint32ToStructField(ptr, field, uint32(scanField.IntVal))
-				break
-			case "string":
-				if n1, err := strconv.ParseInt(scanField.StringVal, 10, 32); err == nil {
-					setUint32ToStructField(ptr, field, uint32(n1))
-				}
+			break
+		case "NullInt32":
+			if scanField.NullInt32Val.Valid {
+				setUint32ToStructField(fv, uint32(scanField.NullInt32Val.Int32))
+			}
 
-				break
+			break
+		case "int64":
+			setUint32ToStructField(fv, uint32(scanField.Int64Val))
+			break
+		case "int":
+			setUint32ToStructField(fv, uint32(scanField.IntVal))
+			break
+		case "string":
+			if n1, err := strconv.ParseInt(scanField.StringVal, 10, 32); err == nil {
+				setUint32ToStructField(fv, uint32(n1))
 			}
 
-			continue
-		case reflect.Uint64:
-			switch scanField.TypeName {
-			case "NullInt64":
-				if scanField.NullInt64Val.Valid {
-					setUint64ToStructField(ptr, field, uint64(scanField.NullInt64Val.Int64))
-				}
+			break
+		}
 
-				break
-			case "NullInt32":
-				if scanField.NullInt32Val.Valid {
-					setUint64ToStructField(ptr, field, uint64(scanField.NullInt32Val.Int32))
-				}
+		return nil
+	case reflect.Uint64:
+		switch scanField.TypeName {
+		case "NullInt64":
+			if scanField.NullInt64Val.Valid {
+				setUint64ToStructField(fv, uint64(scanField.NullInt64Val.Int64))
+			}
 
-				break
-			case "int64":
-				setUint64ToStructField(ptr, field, uint64(scanField.Int64Val))
-				break
-			case "int":
-				setUint64ToStructField(ptr, field, uint64(scanField.IntVal))
-				break
-			case "string":
-				if n1, err := strconv.ParseInt(scanField.StringVal, 10, 64); err == nil {
-					setUint64ToStructField(ptr, field, uint64(n1))
-				}
+			break
+		case "NullInt32":
+			if scanField.NullInt32Val.Valid {
+				setUint64ToStructField(fv, uint64(scanField.NullInt32Val.Int32))
+			}
 
-				break
+			break
+		case "int64":
+			setUint64ToStructField(fv, uint64(scanField.Int64Val))
+			break
+		case "int":
+			setUint64ToStructField(fv, uint64(scanField.IntVal))
+			break
+		case "string":
+			if n1, err := strconv.ParseInt(scanField.StringVal, 10, 64); err == nil {
+				setUint64ToStructField(fv, uint64(n1))
 			}
 
-			continue
-		case reflect.Float32:
-			switch scanField.TypeName {
-			case "NullFloat64":
-				if scanField.NullFloat64Val.Valid {
-					setFloat32ToStructField(ptr, field, float32(scanField.NullFloat64Val.Float64))
-				}
+			break
+		}
 
-				break
-			case "float64":
-				setFloat32ToStructField(ptr, field, float32(scanField.Float64Val))
-				break
-			case "string":
-				if n1, err := strconv.ParseFloat(scanField.StringVal, 32); err == nil {
-					setFloat32ToStructField(ptr, field, float32(n1))
-				}
+		return nil
+	case reflect.Float32:
+		switch scanField.TypeName {
+		case "NullFloat64":
+			if scanField.NullFloat64Val.Valid {
+				setFloat32ToStructField(fv, float32(scanField.NullFloat64Val.Float64))
+			}
 
-				break
+			break
+		case "float64":
+			setFloat32ToStructField(fv, float32(scanField.Float64Val))
+			break
+		case "string":
+			if n1, err := strconv.ParseFloat(scanField.StringVal, 32); err == nil {
+				setFloat32ToStructField(fv, float32(n1))
 			}
 
-			continue
-		case reflect.Float64:
-			switch scanField.TypeName {
-			case "NullFloat64":
-				if scanField.NullFloat64Val.Valid {
-					setFloat64ToStructField(ptr, field, scanField.NullFloat64Val.Float64)
-				}
+			break
+		}
 
-				break
-			case "float64":
-				setFloat64ToStructField(ptr, field, scanField.Float64Val)
-				break
-			case "string":
-				if n1, err := strconv.ParseFloat(scanField.StringVal, 64); err == nil {
-					setFloat64ToStructField(ptr, field, n1)
-				}
+		return nil
+	case reflect.Float64:
+		switch scanField.TypeName {
+		case "NullFloat64":
+			if scanField.NullFloat64Val.Valid {
+				setFloat64ToStructField(fv, scanField.NullFloat64Val.Float64)
+			}
 
-				break
+			break
+		case "float64":
+			setFloat64ToStructField(fv, scanField.Float64Val)
+			break
+		case "string":
+			if n1, err := strconv.ParseFloat(scanField.StringVal, 64); err == nil {
+				setFloat64ToStructField(fv, n1)
 			}
 
-			continue
+			break
 		}
 
-		fieldType := fmt.Sprintf("%v", field.Type)
-
-		switch fieldType {
-		case "time.Time":
-			switch scanField.TypeName {
-			case "NullTime":
-				if scanField.NullTimeVal.Valid {
-					setTimeToStructField(ptr, field, scanField.NullTimeVal.Time)
-				}
+		return nil
+	}
 
-				break
-			case "time":
-				setTimeToStructField(ptr, field, scanField.TimeVal)
-				break
-			case "string":
-				s1 := scanField.StringVal
-
-				if d1, err := time.ParseInLocation(dateFormatFull, s1, time.Local); err == nil {
-					setTimeToStructField(ptr, field, d1)
-				} else if d1, err := time.ParseInLocation(dateFormatDateOnly, s1, time.Local); err == nil {
-					setTimeToStructField(ptr, field, d1)
-				}
+	fieldType := fmt.Sprintf("%v", field.Type)
 
-				break
+	switch fieldType {
+	case "time.Time":
+		switch scanField.TypeName {
+		case "NullTime":
+			if scanField.NullTimeVal.Valid {
+				setTimeToStructField(fv, scanField.NullTimeVal.Time)
 			}
 
 			break
-		case "*time.Time":
-			switch scanField.TypeName {
-			case "NullTime":
-				if scanField.NullTimeVal.Valid {
-					setTimePtrToStructField(ptr, field, &scanField.NullTimeVal.Time)
-				}
-
-				break
-			case "time":
-				t1 := scanField.TimeVal
-				setTimePtrToStructField(ptr, field, &t1)
-				break
-			case "string":
-				s1 := scanField.StringVal
-
-				if d1, err := time.ParseInLocation(dateFormatFull, s1, time.Local); err == nil {
-					setTimePtrToStructField(ptr, field, &d1)
-				} else if d1, err := time.ParseInLocation(dateFormatDateOnly, s1, time.Local); err == nil {
-					setTimePtrToStructField(ptr, field, &d1)
-				}
+		case "time":
+			setTimeToStructField(fv, scanField.TimeVal)
+			break
+		case "string":
+			s1 := scanField.StringVal
 
-				break
+			if d1, err := time.ParseInLocation(dateFormatFull, s1, time.Local); err == nil {
+				setTimeToStructField(fv, d1)
+			} else if d1, err := time.ParseInLocation(dateFormatDateOnly, s1, time.Local); err == nil {
+				setTimeToStructField(fv, d1)
 			}
 
 			break
 		}
-	}
-
-	return nil
-}
-
-func getColumnIdxByGormTag(tag reflect.StructTag, columnNames []string) int {
-	s1 := tag.Get("gorm")
 
-	if s1 == "" {
-		return -1
-	}
-
-	matches := regexpGormColumn.FindStringSubmatch(s1)
+		break
+	case "*time.Time":
+		switch scanField.TypeName {
+		case "NullTime":
+			if scanField.NullTimeVal.Valid {
+				setTimePtrToStructField(fv, &scanField.NullTimeVal.Time)
+			}
 
-	if len(matches) < 2 {
-		return -1
-	}
+			break
+		case "time":
+			t1 := scanField.TimeVal
+			setTimePtrToStructField(fv, &t1)
+			break
+		case "string":
+			s1 := scanField.StringVal
 
-	s2 := strings.ToLower(matches[1])
+			if d1, err := time.ParseInLocation(dateFormatFull, s1, time.Local); err == nil {
+				setTimePtrToStructField(fv, &d1)
+			} else if d1, err := time.ParseInLocation(dateFormatDateOnly, s1, time.Local); err == nil {
+				setTimePtrToStructField(fv, &d1)
+			}
 
-	for idx, columnName := range columnNames {
-		if strings.ToLower(columnName) == s2 {
-			return idx
+			break
 		}
+
+		break
 	}
 
-	return -1
+	return nil
 }
 
 func getColumnIdxByStructFieldName(fieldName string, columnNames []string) int {
@@ -752,9 +701,23 @@ func getColumnIdxByStructFieldName(fieldName string, columnNames []string) int {
 }
 
 func getParamsAndTimeout(args []interface{}) (params []interface{}, timeout time.Duration) {
+	params, timeout, _ = getParamsTimeoutAndContext(args)
+	return
+}
+
+func getParamsTimeoutAndContext(args []interface{}) (params []interface{}, timeout time.Duration, ctx context.Context) {
 	params = make([]interface{}, 0)
+	ctx = context.TODO()
 
 	for _, arg := range args {
+		if c1, ok := arg.(context.Context); ok {
+			if c1 != nil {
+				ctx = c1
+			}
+
+			continue
+		}
+
 		if d1, ok := arg.(time.Duration); ok {
 			if d1 > 0 {
 				timeout = d1
@@ -827,6 +790,10 @@ func getFieldNamesAndTimeout(args []interface{}) (fieldNames []string, timeout t
 }
 
 func getColumnNameBySturctField(tableName, fieldName string, tag reflect.StructTag) string {
+	if dt, ok := parseDbTag(tag); ok && dt.name != "" && dt.name != "-" {
+		return dt.name
+	}
+
 	gormTag := tag.Get("gorm")
 
 	if gormTag != "" {
@@ -837,9 +804,9 @@ func getColumnNameBySturctField(tableName, fieldName string, tag reflect.StructT
 		}
 	}
 
-	schemas, ok := tableSchemas[tableName]
+	schemas := getTableFields(tableName)
 
-	if ok && len(schemas) > 0 {
+	if len(schemas) > 0 {
 		s1 := strings.ToLower(fieldName)
 
 		for _, item := range schemas {
@@ -856,16 +823,60 @@ func getColumnNameBySturctField(tableName, fieldName string, tag reflect.StructT
 	return lcfirst(fieldName)
 }
 
+func normalizeTableName(tableName string) string {
+	if strings.Contains(tableName, ".") {
+		tableName = substringAfter(tableName, ".")
+	}
+
+	return strings.NewReplacer("`", "", `"`, "").Replace(tableName)
+}
+
+func getTableSchema(tableName string) *TableSchema {
+	tableSchemasLock.RLock()
+	defer tableSchemasLock.RUnlock()
+	return tableSchemas[normalizeTableName(tableName)]
+}
+
+func getTableFields(tableName string) []TableFieldInfo {
+	schema := getTableSchema(tableName)
+
+	if schema == nil {
+		return nil
+	}
+
+	return schema.Fields
+}
+
+func getPkColumnName(tableName string) string {
+	schemas := getTableFields(tableName)
+
+	if len(schemas) < 1 {
+		return ""
+	}
+
+	for _, item := range schemas {
+		if item.IsPrimaryKey {
+			return item.FieldName
+		}
+	}
+
+	return ""
+}
+
 func isPkField(tableName, columnName string, tag reflect.StructTag) bool {
+	if hasDbTagOption(tag, "pk") {
+		return true
+	}
+
 	gormTag := tag.Get("gorm")
 
-	if gormTag != "" && strings.Contains(gormTag, "primary_key") {
+	if gormTag != "" && (strings.Contains(gormTag, "primary_key") || strings.Contains(gormTag, "primaryKey")) {
 		return true
 	}
 
-	schemas, ok := tableSchemas[tableName]
+	schemas := getTableFields(tableName)
 
-	if !ok || len(schemas) < 1 {
+	if len(schemas) < 1 {
 		return false
 	}
 
@@ -878,56 +889,73 @@ func isPkField(tableName, columnName string, tag reflect.StructTag) bool {
 	return false
 }
 
-func autoAddCreateTime(tableName string, data map[string]interface{}) {
-	if strings.Contains(tableName, ".") {
-		tableName = substringAfter(tableName, ".")
+func isVersionField(tableName, columnName string, field reflect.StructField) bool {
+	fieldType := field.Type
+
+	if fieldType.Kind() == reflect.Ptr {
+		return false
 	}
 
-	tableName = strings.ReplaceAll(tableName, "`", "")
-	schemas, ok := tableSchemas[tableName]
+	switch fieldType.Kind() {
+	case reflect.Int, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+	default:
+		return false
+	}
 
-	if !ok {
-		return
+	if hasDbTagOption(field.Tag, "version") {
+		return true
 	}
 
-	fieldNames := []string{
-		"ctime",
-		"create_at",
-		"createAt",
-		"create_time",
-		"createTime",
+	return inStringSlice(columnName, getConventions(tableName).versionColumns)
+}
+
+func hasDbTagOption(tag reflect.StructTag, option string) bool {
+	s1 := tag.Get("db")
+
+	if s1 == "" {
+		return false
 	}
 
-	for _, item := range schemas {
-		if slicex.InStringSlice(item.FieldName, fieldNames) && item.FieldType == "datetime" {
-			data[item.FieldName] = time.Now().Format(dateFormatFull)
-			break
+	parts := strings.Split(s1, ",")
+
+	for _, part := range parts[1:] {
+		if strings.TrimSpace(part) == option {
+			return true
 		}
 	}
+
+	return false
 }
 
-func autoAddUpdateTime(tableName string, data map[string]interface{}) {
-	if strings.Contains(tableName, ".") {
-		tableName = substringAfter(tableName, ".")
-	}
+func autoAddCreateTime(tableName string, data map[string]interface{}) {
+	c := getConventions(tableName)
 
-	tableName = strings.ReplaceAll(tableName, "`", "")
-	schemas, ok := tableSchemas[tableName]
+	field, ok := c.findField(tableName, c.createTimeColumns, func(item TableFieldInfo) bool {
+		return getTimeColumnKind(item) != ""
+	})
 
 	if !ok {
 		return
 	}
 
-	fieldNames := []string{
-		"update_at",
-		"updateAt",
+	if value, ok := c.timeValueForField(field, nil); ok {
+		data[field.FieldName] = value
 	}
+}
 
-	for _, item := range schemas {
-		if slicex.InStringSlice(item.FieldName, fieldNames) && item.FieldType == "datetime" {
-			data[item.FieldName] = time.Now().Format(dateFormatFull)
-			return
-		}
+func autoAddUpdateTime(tableName string, data map[string]interface{}) {
+	c := getConventions(tableName)
+
+	field, ok := c.findField(tableName, c.updateTimeColumns, func(item TableFieldInfo) bool {
+		return getTimeColumnKind(item) != ""
+	})
+
+	if !ok {
+		return
+	}
+
+	if value, ok := c.timeValueForField(field, nil); ok {
+		data[field.FieldName] = value
 	}
 }
 
@@ -937,7 +965,7 @@ func substringBefore(str, delimiter string, last ...bool) string {
 	if len(last) > 0 && last[0] {
 		idx = strings.LastIndex(str, delimiter)
 	} else {
-		strings.Index(str, delimiter)
+		idx = strings.Index(str, delimiter)
 	}
 
 	if idx < 1 {
@@ -953,7 +981,7 @@ func substringAfter(str, delimiter string, last ...bool) string {
 	if len(last) > 0 && last[0] {
 		idx = strings.LastIndex(str, delimiter)
 	} else {
-		strings.Index(str, delimiter)
+		idx = strings.Index(str, delimiter)
 	}
 
 	if idx < 0 {
@@ -1178,6 +1206,39 @@ func toDecimalString(arg0 interface{}) string {
 	return p1 + "." + p2
 }
 
+func isListValue(arg0 interface{}) bool {
+	if arg0 == nil {
+		return false
+	}
+
+	if _, ok := arg0.([]byte); ok {
+		return false
+	}
+
+	kind := reflect.TypeOf(arg0).Kind()
+	return kind == reflect.Slice || kind == reflect.Array
+}
+
+func sortedMapKeys(map1 map[string]interface{}) []string {
+	keys := make([]string, 0, len(map1))
+
+	for key := range map1 {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
+func isTruthy(str string) bool {
+	switch strings.ToLower(str) {
+	case "1", "t", "true", "y", "yes", "on":
+		return true
+	}
+
+	return false
+}
+
 func inStringSlice(needle string, array []string, ignoreCase ...bool) bool {
 	if needle == "" || len(array) < 1 {
 		return false
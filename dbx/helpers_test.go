@@ -0,0 +1,52 @@
+package dbx
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	_ "modernc.org/sqlite"
+)
+
+func openSqliteTestDb(t testing.TB, ddl ...string) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "test.db"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, stmt := range ddl {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+
+	WithPool(db, NewSqliteDialect())
+
+	if err := BuildTableSchemas(); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		pool = nil
+		dialect = nil
+		tableSchemasLock.Lock()
+		tableSchemas = map[string]*TableSchema{}
+		tableSchemasLock.Unlock()
+		_ = db.Close()
+	})
+
+	return db
+}
+
+func countRows(t testing.TB, db *sql.DB, query string, args ...interface{}) int {
+	t.Helper()
+	var n int
+
+	if err := db.QueryRow(query, args...).Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+
+	return n
+}
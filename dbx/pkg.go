@@ -3,23 +3,21 @@ package dbx
 import (
 	"database/sql"
 	"github.com/meiguonet/mgboot-go-common/logx"
+	"sync"
 	"time"
 )
 
 var pool *sql.DB
+var dialect Dialect
 var logger logx.Logger
 var debugMode bool
+var softDeleteScopeDisabled bool
 
-type tableFieldInfo struct {
-	FieldName     string
-	FieldType     string
-	FieldSize     int
-	Unsigned      bool
-	Nullable      bool
-	DefaultValue  string
-	AutoIncrement bool
-	IsPrimaryKey  bool
-}
+const (
+	trashedModeWithout = iota
+	trashedModeWith
+	trashedModeOnly
+)
 
 type table struct {
 	name  string
@@ -37,12 +35,18 @@ func (t *table) nameWithAlias() string {
 }
 
 type column struct {
-	name  string
-	alias string
+	name     string
+	alias    string
+	expr     string
+	bindings []interface{}
 }
 
 func (c *column) nameWithAlias() string {
-	name := quote(c.name)
+	name := c.expr
+
+	if name == "" {
+		name = quote(c.name)
+	}
 
 	if c.alias == "" {
 		return name
@@ -58,7 +62,9 @@ type joinClause struct {
 }
 
 type rawSql struct {
-	expr string
+	expr     string
+	bindings []interface{}
+	err      error
 }
 
 type scanField struct {
@@ -78,10 +84,36 @@ type scanField struct {
 	InterfaceVal   interface{}
 }
 
-var tableSchemas map[string][]tableFieldInfo
+var tableSchemas = map[string]*TableSchema{}
+var tableSchemasLock = &sync.RWMutex{}
 
-func WithPool(arg0 *sql.DB) {
+// WithPool sets the connection pool every query builder uses. The package keeps a single pool
+// and a single dialect, so one process talks to one kind of database; calling WithPool again
+// with another dialect switches SQL generation for all callers.
+func WithPool(arg0 *sql.DB, arg1 ...Dialect) {
 	pool = arg0
+	resetAutoIncrementSettings()
+
+	if len(arg1) > 0 && arg1[0] != nil {
+		dialect = arg1[0]
+	}
+}
+
+// WithDialect replaces the package-wide dialect, see WithPool.
+func WithDialect(arg0 Dialect) {
+	dialect = arg0
+}
+
+func GetDialect() Dialect {
+	return getDialect()
+}
+
+func getDialect() Dialect {
+	if dialect == nil {
+		return NewMysqlDialect()
+	}
+
+	return dialect
 }
 
 func WithLogger(arg0 logx.Logger) {
@@ -96,3 +128,12 @@ func DebugModeEnabled(args ...bool) bool {
 
 	return debugMode
 }
+
+func SoftDeleteScopeEnabled(args ...bool) bool {
+	if len(args) > 0 {
+		softDeleteScopeDisabled = !args[0]
+		return false
+	}
+
+	return !softDeleteScopeDisabled
+}
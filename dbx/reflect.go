@@ -3,7 +3,6 @@ package dbx
 import (
 	"reflect"
 	"time"
-	"unsafe"
 )
 
 func indirect(arg0 interface{}) interface{} {
@@ -24,70 +23,50 @@ func indirect(arg0 interface{}) interface{} {
 	return rv.Interface()
 }
 
-func getStructPtr(arg0 interface{}) unsafe.Pointer {
-	return unsafe.Pointer(reflect.ValueOf(arg0).Pointer())
+func setStringToStructField(fv reflect.Value, value string) {
+	fv.SetString(value)
 }
 
-func getStructFieldPtr(structPtr unsafe.Pointer, field reflect.StructField) uintptr {
-	return uintptr(structPtr) + field.Offset
+func setBoolToStructField(fv reflect.Value, value bool) {
+	fv.SetBool(value)
 }
 
-func setStringToStructField(structPtr unsafe.Pointer, field reflect.StructField, value string) {
-	fieldPtr := getStructFieldPtr(structPtr, field)
-	*((*string)(unsafe.Pointer(fieldPtr))) = value
+func setIntToStructField(fv reflect.Value, value int) {
+	fv.SetInt(int64(value))
 }
 
-func setBoolToStructField(structPtr unsafe.Pointer, field reflect.StructField, value bool) {
-	fieldPtr := getStructFieldPtr(structPtr, field)
-	*((*bool)(unsafe.Pointer(fieldPtr))) = value
+func setInt32ToStructField(fv reflect.Value, value int32) {
+	fv.SetInt(int64(value))
 }
 
-func setIntToStructField(structPtr unsafe.Pointer, field reflect.StructField, value int) {
-	fieldPtr := getStructFieldPtr(structPtr, field)
-	*((*int)(unsafe.Pointer(fieldPtr))) = value
+func setInt64ToStructField(fv reflect.Value, value int64) {
+	fv.SetInt(value)
 }
 
-func setInt32ToStructField(structPtr unsafe.Pointer, field reflect.StructField, value int32) {
-	fieldPtr := getStructFieldPtr(structPtr, field)
-	*((*int32)(unsafe.Pointer(fieldPtr))) = value
+func setUintToStructField(fv reflect.Value, value uint) {
+	fv.SetUint(uint64(value))
 }
 
-func setInt64ToStructField(structPtr unsafe.Pointer, field reflect.StructField, value int64) {
-	fieldPtr := getStructFieldPtr(structPtr, field)
-	*((*int64)(unsafe.Pointer(fieldPtr))) = value
+func setUint32ToStructField(fv reflect.Value, value uint32) {
+	fv.SetUint(uint64(value))
 }
 
-func setUintToStructField(structPtr unsafe.Pointer, field reflect.StructField, value uint) {
-	fieldPtr := getStructFieldPtr(structPtr, field)
-	*((*uint)(unsafe.Pointer(fieldPtr))) = value
+func setUint64ToStructField(fv reflect.Value, value uint64) {
+	fv.SetUint(value)
 }
 
-func setUint32ToStructField(structPtr unsafe.Pointer, field reflect.StructField, value uint32) {
-	fieldPtr := getStructFieldPtr(structPtr, field)
-	*((*uint32)(unsafe.Pointer(fieldPtr))) = value
+func setFloat32ToStructField(fv reflect.Value, value float32) {
+	fv.SetFloat(float64(value))
 }
 
-func setUint64ToStructField(structPtr unsafe.Pointer, field reflect.StructField, value uint64) {
-	fieldPtr := getStructFieldPtr(structPtr, field)
-	*((*uint64)(unsafe.Pointer(fieldPtr))) = value
+func setFloat64ToStructField(fv reflect.Value, value float64) {
+	fv.SetFloat(value)
 }
 
-func setFloat32ToStructField(structPtr unsafe.Pointer, field reflect.StructField, value float32) {
-	fieldPtr := getStructFieldPtr(structPtr, field)
-	*((*float32)(unsafe.Pointer(fieldPtr))) = value
+func setTimeToStructField(fv reflect.Value, value time.Time) {
+	fv.Set(reflect.ValueOf(value))
 }
 
-func setFloat64ToStructField(structPtr unsafe.Pointer, field reflect.StructField, value float64) {
-	fieldPtr := getStructFieldPtr(structPtr, field)
-	*((*float64)(unsafe.Pointer(fieldPtr))) = value
-}
-
-func setTimeToStructField(structPtr unsafe.Pointer, field reflect.StructField, value time.Time) {
-	fieldPtr := getStructFieldPtr(structPtr, field)
-	*((*time.Time)(unsafe.Pointer(fieldPtr))) = value
-}
-
-func setTimePtrToStructField(structPtr unsafe.Pointer, field reflect.StructField, value *time.Time) {
-	fieldPtr := getStructFieldPtr(structPtr, field)
-	*((**time.Time)(unsafe.Pointer(fieldPtr))) = value
+func setTimePtrToStructField(fv reflect.Value, value *time.Time) {
+	fv.Set(reflect.ValueOf(value))
 }
@@ -7,4 +7,5 @@ require (
 	github.com/go-sql-driver/mysql v1.6.0
 	github.com/gomodule/redigo v1.8.5
 	github.com/meiguonet/mgboot-go-common v1.0.9
+	modernc.org/sqlite v1.14.6
 )
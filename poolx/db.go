@@ -6,10 +6,12 @@ import (
 	"github.com/go-sql-driver/mysql"
 	"github.com/meiguonet/mgboot-go-common/AppConf"
 	"github.com/meiguonet/mgboot-go-common/util/castx"
+	"strings"
 	"time"
 )
 
 var dbPool *sql.DB
+var dbDriverName string
 
 func InitDbPool(settings ...map[string]interface{}) {
 	var _settings map[string]interface{}
@@ -22,9 +24,25 @@ func InitDbPool(settings ...map[string]interface{}) {
 		_settings = AppConf.GetMap("datasource")
 	}
 
-	dsn := buildDsn(_settings)
+	dbDriverName = castx.ToString(_settings["driver"])
+
+	if dbDriverName == "" {
+		dbDriverName = "mysql"
+	}
+
+	var dsn string
+
+	switch dbDriverName {
+	case "postgres", "pgx":
+		dsn = buildPostgresDsn(_settings)
+	case "sqlite3", "sqlite":
+		dsn = castx.ToString(_settings["database"])
+	default:
+		dsn = buildDsn(_settings)
+	}
+
 	var err error
-	dbPool, err = sql.Open("mysql", dsn)
+	dbPool, err = sql.Open(dbDriverName, dsn)
 
 	if err != nil {
 		panic(err)
@@ -61,6 +79,10 @@ func GetDbPool() *sql.DB {
 	return dbPool
 }
 
+func GetDbDriverName() string {
+	return dbDriverName
+}
+
 func CloseDbPool() {
 	dbPool.Close()
 }
@@ -98,3 +120,48 @@ func buildDsn(settings map[string]interface{}) string {
 	cfg.Params = params
 	return cfg.FormatDSN()
 }
+
+func buildPostgresDsn(settings map[string]interface{}) string {
+	host := castx.ToString(settings["host"])
+
+	if host == "" {
+		host = "127.0.0.1"
+	}
+
+	port := castx.ToInt(settings["port"])
+
+	if port < 1 {
+		port = 5432
+	}
+
+	sslMode := castx.ToString(settings["sslmode"])
+
+	if sslMode == "" {
+		sslMode = "disable"
+	}
+
+	parts := []string{
+		"host=" + quotePostgresDsnValue(host),
+		fmt.Sprintf("port=%d", port),
+		"user=" + quotePostgresDsnValue(castx.ToString(settings["username"])),
+		"password=" + quotePostgresDsnValue(castx.ToString(settings["password"])),
+		"dbname=" + quotePostgresDsnValue(castx.ToString(settings["database"])),
+		"sslmode=" + quotePostgresDsnValue(sslMode),
+	}
+
+	if tz := castx.ToString(settings["loc"]); tz != "" {
+		parts = append(parts, "TimeZone="+quotePostgresDsnValue(tz))
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// quotePostgresDsnValue quotes value the way libpq parses keyword/value connection strings, so
+// passwords with spaces, quotes or backslashes survive.
+func quotePostgresDsnValue(value string) string {
+	if value != "" && !strings.ContainsAny(value, " \t\n\r'\\") {
+		return value
+	}
+
+	return "'" + strings.NewReplacer(`\`, `\\`, "'", `\'`).Replace(value) + "'"
+}
@@ -0,0 +1,24 @@
+package poolx
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuildPostgresDsnQuotesValues(t *testing.T) {
+	dsn := buildPostgresDsn(map[string]interface{}{
+		"username": "app",
+		"password": `p a'ss\w`,
+		"database": "shop",
+	})
+
+	for _, want := range []string{"user=app ", `password='p a\'ss\\w' `, "dbname=shop ", "sslmode=disable"} {
+		if !strings.Contains(dsn, want) {
+			t.Errorf("dsn %q does not contain %q", dsn, want)
+		}
+	}
+
+	if dsn := buildPostgresDsn(map[string]interface{}{"username": "app"}); !strings.Contains(dsn, "password='' ") {
+		t.Errorf("empty password not quoted in %q", dsn)
+	}
+}